@@ -0,0 +1,184 @@
+package goerr
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// BatchError 批量操作的部分失败错误，记录每个失败项的下标或键及其错误
+// 可被多个协程并发记录
+type BatchError struct {
+	mu    sync.Mutex
+	total int
+	items []BatchItem
+}
+
+// BatchItem 批量操作中失败的一项
+type BatchItem struct {
+	// Index 失败项的下标，使用键记录时为-1
+	Index int
+	// Key 失败项的键，使用下标记录时为空
+	Key string
+	// Err 失败项的错误
+	Err error
+}
+
+type batchItemJSON struct {
+	Index        *int   `json:"index,omitempty"`
+	Key          string `json:"key,omitempty"`
+	BusinessCode int    `json:"businessCode"`
+	HttpCode     int    `json:"httpCode"`
+	Message      string `json:"message"`
+}
+
+// NewBatch 创建批量操作错误，total为批量操作的总项数
+func NewBatch(total int) *BatchError {
+	return &BatchError{total: total}
+}
+
+// Add 记录下标为index的项失败，err为nil时忽略
+func (b *BatchError) Add(index int, err error) {
+	if err != nil {
+		b.add(BatchItem{Index: index, Err: wrapStack(err)})
+	}
+}
+
+// AddKey 记录键为key的项失败，err为nil时忽略
+func (b *BatchError) AddKey(key string, err error) {
+	if err != nil {
+		b.add(BatchItem{Index: -1, Key: key, Err: wrapStack(err)})
+	}
+}
+
+func (b *BatchError) add(item BatchItem) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.items = append(b.items, item)
+}
+
+// Len 批量操作的总项数
+func (b *BatchError) Len() int { return b.total }
+
+// Failed 获取全部失败项，按下标排序，使用键记录的项按记录顺序排在最前
+func (b *BatchError) Failed() []BatchItem {
+	b.mu.Lock()
+	items := slices.Clone(b.items)
+	b.mu.Unlock()
+	slices.SortStableFunc(items, func(x, y BatchItem) int {
+		return cmp.Compare(x.Index, y.Index)
+	})
+	return items
+}
+
+// Range 按照 Failed 的顺序遍历失败项，f返回false时停止遍历
+func (b *BatchError) Range(f func(item BatchItem) bool) {
+	for _, item := range b.Failed() {
+		if !f(item) {
+			return
+		}
+	}
+}
+
+// Err 存在失败项时返回自身，否则返回nil
+func (b *BatchError) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.items) == 0 {
+		return nil
+	}
+	return b
+}
+
+func (b *BatchError) Error() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return fmt.Sprintf("%d of %d items failed", len(b.items), b.total)
+}
+
+func (b *BatchError) Unwrap() []error {
+	items := b.Failed()
+	errs := make([]error, len(items))
+	for i, item := range items {
+		errs[i] = item.Err
+	}
+	return errs
+}
+
+func (b *BatchError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			printVerbose(s, b)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, b.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", b.Error())
+	}
+}
+
+// MarshalJSON 序列化为失败项数组，每一项包含下标或键、业务码、HTTP码与错误信息
+func (b *BatchError) MarshalJSON() ([]byte, error) {
+	items := b.Failed()
+	ret := make([]batchItemJSON, len(items))
+	for i, item := range items {
+		code := ParseCode(item.Err)
+		ret[i] = batchItemJSON{
+			Key:          item.Key,
+			BusinessCode: code.BusinessCode,
+			HttpCode:     code.HttpCode,
+			Message:      code.Msg,
+		}
+		if item.Index >= 0 {
+			ret[i].Index = &item.Index
+		}
+	}
+	return json.Marshal(ret)
+}
+
+// HttpCode 批量操作整体的HTTP码
+// 部分失败时为207，全部失败时为失败项中出现次数最多的HTTP码，没有失败项时为200
+func (b *BatchError) HttpCode() int {
+	code := b.code()
+	if code == nil {
+		return http.StatusOK
+	}
+	return code.HttpCode
+}
+
+// code 批量操作整体的错误码，没有失败项时返回nil
+// 部分失败时HTTP码为207，业务码为应用码；全部失败时使用出现次数最多的HTTP码对应的首个错误码
+func (b *BatchError) code() *withCode {
+	items := b.Failed()
+	if len(items) == 0 {
+		return nil
+	}
+	if len(items) < b.total {
+		return &withCode{
+			Msg:          b.Error(),
+			HttpCode:     http.StatusMultiStatus,
+			BusinessCode: serviceCode.Load(),
+		}
+	}
+
+	codes := make([]*withCode, len(items))
+	counts := make(map[int]int)
+	for i, item := range items {
+		codes[i] = ParseCode(item.Err)
+		counts[codes[i].HttpCode]++
+	}
+	dominant := codes[0]
+	for _, code := range codes {
+		if counts[code.HttpCode] > counts[dominant.HttpCode] {
+			dominant = code
+		}
+	}
+	return dominant
+}
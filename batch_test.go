@@ -0,0 +1,109 @@
+package goerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestBatchSuite struct {
+	suite.Suite
+	batch *BatchError
+}
+
+func (s *TestBatchSuite) SetupTest() {
+	NewNotFound(3401, "item not found")
+	NewBadRequest(3402, "invalid item")
+
+	s.batch = NewBatch(5)
+	s.batch.Add(3, WithCode[int](nil, 3402))
+	s.batch.Add(1, WithCode[int](New("missing"), 3401))
+	s.batch.Add(2, nil)
+	s.batch.AddKey("sku-1", WithCode[int](nil, 3401))
+}
+
+func (s *TestBatchSuite) TestBatch() {
+	s.Equal(5, s.batch.Len())
+	s.Equal("3 of 5 items failed", s.batch.Error())
+	s.Nil(NewBatch(5).Err())
+	s.Equal(s.batch, s.batch.Err())
+
+	failed := s.batch.Failed()
+	s.Len(failed, 3)
+	s.Equal("sku-1", failed[0].Key)
+	s.Equal(1, failed[1].Index)
+	s.Equal(3, failed[2].Index)
+
+	var indexes []int
+	s.batch.Range(func(item BatchItem) bool {
+		indexes = append(indexes, item.Index)
+		return item.Index < 1
+	})
+	s.Equal([]int{-1, 1}, indexes)
+
+	s.True(IsCode(s.batch, 3401))
+}
+
+func (s *TestBatchSuite) TestConcurrent() {
+	batch := NewBatch(100)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			batch.Add(i, errors.New("failed"))
+		}()
+	}
+	wg.Wait()
+	failed := batch.Failed()
+	s.Len(failed, 100)
+	s.Equal(99, failed[99].Index)
+}
+
+func (s *TestBatchSuite) TestMarshalJSON() {
+	data, err := json.Marshal(s.batch)
+	s.NoError(err)
+	base := serviceCode.Load()
+	s.JSONEq(fmt.Sprintf(`[
+		{"key":"sku-1","businessCode":%d,"httpCode":404,"message":"item not found"},
+		{"index":1,"businessCode":%d,"httpCode":404,"message":"item not found"},
+		{"index":3,"businessCode":%d,"httpCode":400,"message":"invalid item"}
+	]`, base+3401, base+3401, base+3402), string(data))
+}
+
+func (s *TestBatchSuite) TestParseCode() {
+	code := ParseCode(Wrap(s.batch, "import failed"))
+	s.Equal(http.StatusMultiStatus, code.HttpCode)
+	s.Equal(serviceCode.Load(), code.BusinessCode)
+	s.Equal("import failed", code.Msg)
+	s.Equal(http.StatusMultiStatus, s.batch.HttpCode())
+
+	all := NewBatch(3)
+	all.Add(0, WithCode[int](nil, 3402))
+	all.Add(1, WithCode[int](nil, 3401))
+	all.Add(2, WithCode[int](nil, 3401))
+	code = ParseCode(all)
+	s.Equal(http.StatusNotFound, code.HttpCode)
+	s.Equal(serviceCode.Load()+3401, code.BusinessCode)
+	s.Equal("3 of 3 items failed", code.Msg)
+
+	s.Equal(http.StatusOK, NewBatch(1).HttpCode())
+}
+
+func (s *TestBatchSuite) TestFormat() {
+	out := fmt.Sprintf("%+v", s.batch)
+	notFound := fmt.Sprintf("item not found [biz=%d http=404]", serviceCode.Load()+3401)
+	s.True(strings.HasPrefix(out, "3 of 5 items failed:\n  - [\"sku-1\"] "+notFound+"\n"))
+	s.Contains(out, "\n  - [1] "+notFound+"\n    missing\n")
+	s.Contains(out, "\n  - [3] invalid item [biz=")
+}
+
+func TestBatch(t *testing.T) {
+	suite.Run(t, &TestBatchSuite{})
+}
@@ -21,6 +21,11 @@ func register(code ErrCode) {
 	codeMap.Store(code.BusinessCode, code)
 }
 
+// LookupCode 根据完整的业务码获取已注册的错误码
+func LookupCode(businessCode int) (ErrCode, bool) {
+	return codeMap.Load(businessCode)
+}
+
 func getCode(business int) ErrCode {
 	code, ok := codeMap.Load(business)
 	if ok {
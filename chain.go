@@ -0,0 +1,156 @@
+package goerr
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+)
+
+// maxDepth 遍历错误链的最大深度
+var maxDepth atomic.Int64
+
+func init() {
+	maxDepth.Store(64)
+}
+
+// SetMaxDepth 设置遍历错误链的最大深度，默认为64
+// 格式化输出、UnWrap、Is、As、ParseCode 等遍历错误链的操作在超过该深度后停止
+func SetMaxDepth(depth int) {
+	maxDepth.Store(int64(max(depth, 1)))
+}
+
+type chainState int
+
+const (
+	chainOK chainState = iota
+	chainCycle
+	chainTooDeep
+)
+
+// marker 格式化输出中代替剩余错误链的标记
+func (s chainState) marker() string {
+	if s == chainCycle {
+		return "... (cycle detected)"
+	}
+	return "... (max depth " + strconv.FormatInt(maxDepth.Load(), 10) + " exceeded)"
+}
+
+// chainGuard 遍历错误链时记录从根到当前错误的路径，用于检测循环引用并限制深度
+// 同一个错误出现在多错误的不同分支中不视为循环
+type chainGuard struct {
+	path []error
+}
+
+// enter 进入err，返回是否可以继续遍历
+func (g *chainGuard) enter(err error) chainState {
+	if int64(len(g.path)) >= maxDepth.Load() {
+		return chainTooDeep
+	}
+	// 不可比较的错误类型无法判断是否重复，只能依靠深度限制
+	if reflect.TypeOf(err).Comparable() {
+		for _, e := range g.path {
+			if e == err {
+				return chainCycle
+			}
+		}
+	}
+	g.path = append(g.path, err)
+	return chainOK
+}
+
+// mark 记录当前路径长度，配合 reset 在遍历完分支后回退路径
+func (g *chainGuard) mark() int { return len(g.path) }
+
+func (g *chainGuard) reset(mark int) { g.path = g.path[:mark] }
+
+// unwrapChain 沿 Unwrap() error 遍历错误链，f返回false时停止
+func unwrapChain(err error, f func(err error) bool) {
+	var g chainGuard
+	for err != nil && g.enter(err) == chainOK {
+		if !f(err) {
+			return
+		}
+		err = errors.Unwrap(err)
+	}
+}
+
+// walkTree 先序遍历由 Unwrap() error 与 Unwrap() []error 组成的错误树，
+// f返回true时停止遍历，返回值表示遍历是否被f停止
+func walkTree(err error, g *chainGuard, f func(err error) bool) bool {
+	defer g.reset(g.mark())
+	for err != nil {
+		if g.enter(err) != chainOK {
+			return false
+		}
+		if f(err) {
+			return true
+		}
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, branch := range e.Unwrap() {
+				if walkTree(branch, g, f) {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// is 与 errors.Is 语义相同，但会检测循环引用并限制深度
+func is(err, target error) bool {
+	if err == nil || target == nil {
+		return err == target
+	}
+	comparable := reflect.TypeOf(target).Comparable()
+	return walkTree(err, &chainGuard{}, func(e error) bool {
+		if comparable && e == target {
+			return true
+		}
+		x, ok := e.(interface{ Is(error) bool })
+		return ok && x.Is(target)
+	})
+}
+
+// sameError 判断两个错误是否为同一个错误，不可比较的错误类型视为不同
+func sameError(a, b error) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// as 与 errors.As 语义相同，但会检测循环引用并限制深度
+func as(err error, target any) bool {
+	if err == nil {
+		return false
+	}
+	if target == nil {
+		panic("goerr: target cannot be nil")
+	}
+	val := reflect.ValueOf(target)
+	typ := val.Type()
+	if typ.Kind() != reflect.Ptr || val.IsNil() {
+		panic("goerr: target must be a non-nil pointer")
+	}
+	targetType := typ.Elem()
+	if targetType.Kind() != reflect.Interface && !targetType.Implements(errorType) {
+		panic("goerr: *target must be interface or implement error")
+	}
+	return walkTree(err, &chainGuard{}, func(e error) bool {
+		if reflect.TypeOf(e).AssignableTo(targetType) {
+			val.Elem().Set(reflect.ValueOf(e))
+			return true
+		}
+		x, ok := e.(interface{ As(any) bool })
+		return ok && x.As(target)
+	})
+}
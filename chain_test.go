@@ -0,0 +1,88 @@
+package goerr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type loopError struct {
+	next error
+}
+
+func (l *loopError) Error() string { return "loop error" }
+func (l *loopError) Unwrap() error { return l.next }
+
+type TestChainSuite struct {
+	suite.Suite
+	loop      *loopError
+	cycleErr  error
+	deepErr   error
+	branchErr error
+}
+
+func (s *TestChainSuite) SetupTest() {
+	s.loop = &loopError{}
+	s.cycleErr = Wrap(s.loop, "wrap error")
+	s.loop.next = s.cycleErr
+
+	s.deepErr = New("origin error")
+	for i := 0; i < 100; i++ {
+		s.deepErr = Wrap(s.deepErr, "wrap %d", i)
+	}
+
+	shared := New("shared error")
+	s.branchErr = Join(shared, Wrap(shared, "wrap shared"))
+}
+
+func (s *TestChainSuite) TearDownTest() {
+	SetMaxDepth(64)
+}
+
+func (s *TestChainSuite) TestCycle() {
+	s.True(strings.HasPrefix(fmt.Sprintf("%+v", s.cycleErr), "wrap error\nloop error\n"))
+
+	s.Equal(s.loop, UnWrap(s.cycleErr))
+	s.False(Is(s.cycleErr, errors.New("other")))
+	s.True(Is(s.cycleErr, s.loop))
+	var code *withCode
+	s.False(As(s.cycleErr, &code))
+	s.Equal("wrap error", ParseCode(s.cycleErr).Msg)
+	s.True(hasStack(s.cycleErr))
+
+	message := &withMessage{msg: "message"}
+	message.cause = &withCode{cause: message, Msg: "code"}
+	s.Equal("message\ncode [biz=0 http=0]\n... (cycle detected)", fmt.Sprintf("%+v", message))
+	s.Equal(message.cause, findCode(message))
+
+	self := &withStack{stack: callers()}
+	self.error = self
+	s.Equal("... (cycle detected)", strings.SplitN(fmt.Sprintf("%+v", self), "\n", 2)[0])
+	s.Equal("", outerMsg(self))
+}
+
+func (s *TestChainSuite) TestDepth() {
+	out := fmt.Sprintf("%+v", s.deepErr)
+	s.Contains(out, "wrap 36\n... (max depth 64 exceeded)")
+	s.NotContains(out, "origin error")
+
+	s.False(Is(s.deepErr, errors.New("other")))
+	s.NotEqual("origin error", UnWrap(s.deepErr).Error())
+
+	SetMaxDepth(200)
+	s.Contains(fmt.Sprintf("%+v", s.deepErr), "origin error")
+	s.Equal("origin error", UnWrap(s.deepErr).Error())
+}
+
+func (s *TestChainSuite) TestSharedBranch() {
+	out := fmt.Sprintf("%+v", s.branchErr)
+	s.NotContains(out, "cycle detected")
+	s.Equal(2, strings.Count(out, "shared error"))
+}
+
+func TestChain(t *testing.T) {
+	suite.Run(t, &TestChainSuite{})
+}
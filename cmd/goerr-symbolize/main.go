@@ -0,0 +1,125 @@
+// goerr-symbolize 将 goerr.EncodeStack 产生的紧凑堆栈还原为 %+v 格式的堆栈
+//
+// 用法：
+//
+//	goerr-symbolize -binary ./service goerr1.xxx
+//	cat stacks.txt | goerr-symbolize -binary ./service
+//
+// 未指定编码字符串时从标准输入逐行读取
+package main
+
+import (
+	"bufio"
+	"debug/elf"
+	"debug/gosym"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yushengji/goerr"
+	"github.com/yushengji/goerr/internal/buildid"
+)
+
+func main() {
+	binary := flag.String("binary", "", "产生错误的服务二进制文件路径")
+	flag.Parse()
+	if *binary == "" {
+		fmt.Fprintln(os.Stderr, "goerr-symbolize: -binary is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	s, err := newSymbolizer(*binary)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goerr-symbolize: %v\n", err)
+		os.Exit(1)
+	}
+
+	encoded := flag.Args()
+	if len(encoded) == 0 {
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				encoded = append(encoded, line)
+			}
+		}
+	}
+
+	failed := false
+	for _, e := range encoded {
+		if err := s.symbolize(os.Stdout, e); err != nil {
+			fmt.Fprintf(os.Stderr, "goerr-symbolize: %v\n", err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+type symbolizer struct {
+	buildID string
+	anchor  uint64
+	table   *gosym.Table
+}
+
+// newSymbolizer 读取二进制文件中的 .gopclntab 段构建符号表
+func newSymbolizer(binary string) (*symbolizer, error) {
+	f, err := elf.Open(binary)
+	if err != nil {
+		return nil, goerr.Wrap(err, "open binary %s", binary)
+	}
+	defer f.Close()
+
+	text := f.Section(".text")
+	pclntab := f.Section(".gopclntab")
+	if text == nil || pclntab == nil {
+		return nil, goerr.New("%s has no .text or .gopclntab section", binary)
+	}
+	data, err := pclntab.Data()
+	if err != nil {
+		return nil, goerr.Wrap(err, "read .gopclntab")
+	}
+	table, err := gosym.NewTable(nil, gosym.NewLineTable(data, text.Addr))
+	if err != nil {
+		return nil, goerr.Wrap(err, "parse .gopclntab")
+	}
+	anchor := table.LookupFunc(goerr.StackAnchor)
+	if anchor == nil {
+		return nil, goerr.New("%s does not contain %s", binary, goerr.StackAnchor)
+	}
+	return &symbolizer{
+		buildID: buildid.Read(binary),
+		anchor:  anchor.Entry,
+		table:   table,
+	}, nil
+}
+
+// symbolize 输出错误信息及符号化后的堆栈，格式与 %+v 一致
+func (s *symbolizer) symbolize(w io.Writer, encoded string) error {
+	stack, err := goerr.DecodeStack(encoded)
+	if err != nil {
+		return err
+	}
+	if stack.BuildID != "" && s.buildID != "" && stack.BuildID != s.buildID {
+		fmt.Fprintf(os.Stderr, "goerr-symbolize: build id mismatch, stack from %s, binary is %s\n",
+			stack.BuildID, s.buildID)
+	}
+
+	io.WriteString(w, stack.Message)
+	for _, offset := range stack.Offsets {
+		// 记录的是返回地址，减一后定位到调用指令所在行
+		pc := uint64(int64(s.anchor)+offset) - 1
+		file, line, fn := s.table.PCToLine(pc)
+		if fn == nil {
+			io.WriteString(w, "\nunknown")
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\n\t%s:%d", fn.Name, file, line)
+	}
+	io.WriteString(w, "\n")
+	return nil
+}
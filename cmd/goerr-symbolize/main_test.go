@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"debug/elf"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yushengji/goerr"
+)
+
+// testExecutable 获取测试二进制文件路径，非ELF格式时跳过测试
+func testExecutable(t *testing.T) string {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	f, err := elf.Open(exe)
+	if err != nil {
+		t.Skip("test binary is not ELF")
+	}
+	f.Close()
+	return exe
+}
+
+func TestSymbolize(t *testing.T) {
+	exe := testExecutable(t)
+	err := goerr.Wrap(goerr.New("origin error"), "symbolize error")
+	encoded := goerr.EncodeStack(err)
+
+	s, e := newSymbolizer(exe)
+	require.NoError(t, e)
+
+	var buf bytes.Buffer
+	require.NoError(t, s.symbolize(&buf, encoded))
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "symbolize error\n"))
+	assert.Contains(t, out, "goerr-symbolize.TestSymbolize\n\t")
+	assert.Contains(t, out, "main_test.go:")
+	assert.Contains(t, out, "testing.tRunner")
+}
+
+func TestSymbolizeInvalid(t *testing.T) {
+	s, err := newSymbolizer(testExecutable(t))
+	require.NoError(t, err)
+	assert.Error(t, s.symbolize(&bytes.Buffer{}, "invalid"))
+}
@@ -0,0 +1,59 @@
+package goerr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestCompatibleSuite struct {
+	suite.Suite
+	pkgErr error
+}
+
+func (s *TestCompatibleSuite) SetupTest() {
+	s.pkgErr = pkgerrors.New("pkg error")
+}
+
+func (s *TestCompatibleSuite) TearDownTest() {
+	SetStackOptions()
+}
+
+func (s *TestCompatibleSuite) TestStackTrace() {
+	type stackTracer interface {
+		StackTrace() pkgerrors.StackTrace
+	}
+	for _, err := range []error{New("new error"), WithStack(errors.New("std error"))} {
+		tracer, ok := err.(stackTracer)
+		s.Require().True(ok)
+		trace := tracer.StackTrace()
+		s.NotEmpty(trace)
+		s.Contains(fmt.Sprintf("%+v", trace[0]), "goerr.(*TestCompatibleSuite).TestStackTrace")
+	}
+
+	SetStackOptions(WithFrameFilter(DropPackage("runtime", "testing")))
+	for _, f := range New("new error").(stackTracer).StackTrace() {
+		s.NotContains(fmt.Sprintf("%n", f), "tRunner")
+	}
+}
+
+func (s *TestCompatibleSuite) TestWrapPkgErrors() {
+	s.Equal(s.pkgErr, WithStack(s.pkgErr))
+	s.Equal(s.pkgErr, Wrap(s.pkgErr, "wrap error").(*withMessage).cause)
+}
+
+func (s *TestCompatibleSuite) TestFormatPkgErrors() {
+	SetStackOptions(WithFrameFilter(DropPackage("runtime")))
+	out := fmt.Sprintf("%+v", Wrap(pkgerrors.Wrap(s.pkgErr, "pkg wrap"), "wrap error"))
+	s.True(strings.HasPrefix(out, "wrap error\npkg wrap: pkg error\n"))
+	s.Equal(1, strings.Count(out, "testing.tRunner"))
+	s.NotContains(out, "runtime.goexit")
+}
+
+func TestCompatible(t *testing.T) {
+	suite.Run(t, &TestCompatibleSuite{})
+}
@@ -0,0 +1,116 @@
+package goerr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/yushengji/goerr/internal/buildid"
+)
+
+// StackAnchor 锚点函数全名
+// 编码时记录程序计数器相对于锚点函数入口的偏移，离线还原时以锚点函数
+// 在二进制文件中的地址为基准，从而不受地址随机化的影响
+const StackAnchor = "github.com/yushengji/goerr.stackAnchor"
+
+const encodedPrefix = "goerr1."
+
+// EncodedStack 解码后的紧凑堆栈信息
+type EncodedStack struct {
+	// BuildID 产生错误的二进制文件的构建ID，无法获取时为空
+	BuildID string
+	// Message 错误信息
+	Message string
+	// Offsets 各栈帧程序计数器相对于锚点函数入口的偏移
+	Offsets []int64
+}
+
+var (
+	buildIDOnce sync.Once
+	buildID     string
+)
+
+// readBuildID 获取当前二进制文件的构建ID，非ELF格式时为空
+var readBuildID = func() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	return buildid.Read(exe)
+}
+
+//go:noinline
+func stackAnchor() {}
+
+// EncodeStack 将错误信息与最外层堆栈编码为紧凑的字符串
+// 堆栈仅包含程序计数器与构建ID，不进行符号化，适用于大量输出日志的场景，
+// 可使用 cmd/goerr-symbolize 结合服务的二进制文件还原为 %+v 格式的堆栈
+func EncodeStack(err error) string {
+	if err == nil {
+		return ""
+	}
+	buildIDOnce.Do(func() { buildID = readBuildID() })
+	anchor := int64(reflect.ValueOf(stackAnchor).Pointer())
+	pcs := stackOf(err)
+	msg := err.Error()
+
+	buf := make([]byte, 0, len(buildID)+len(msg)+len(pcs)*4+16)
+	buf = binary.AppendUvarint(buf, uint64(len(buildID)))
+	buf = append(buf, buildID...)
+	buf = binary.AppendUvarint(buf, uint64(len(msg)))
+	buf = append(buf, msg...)
+	buf = binary.AppendUvarint(buf, uint64(len(pcs)))
+	for _, pc := range pcs {
+		buf = binary.AppendVarint(buf, int64(pc)-anchor)
+	}
+	return encodedPrefix + base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// DecodeStack 解码 EncodeStack 产生的字符串
+func DecodeStack(s string) (*EncodedStack, error) {
+	data, ok := strings.CutPrefix(strings.TrimSpace(s), encodedPrefix)
+	if !ok {
+		return nil, New("invalid encoded stack: missing prefix %q", encodedPrefix)
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return nil, Wrap(err, "invalid encoded stack")
+	}
+
+	r := bytes.NewReader(buf)
+	readString := func() (string, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil || n > uint64(r.Len()) {
+			return "", New("invalid encoded stack: truncated string")
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return "", New("invalid encoded stack: truncated string")
+		}
+		return string(b), nil
+	}
+
+	ret := &EncodedStack{}
+	if ret.BuildID, err = readString(); err != nil {
+		return nil, err
+	}
+	if ret.Message, err = readString(); err != nil {
+		return nil, err
+	}
+	n, err := binary.ReadUvarint(r)
+	if err != nil || n > uint64(r.Len()) {
+		return nil, New("invalid encoded stack: truncated frames")
+	}
+	ret.Offsets = make([]int64, n)
+	for i := range ret.Offsets {
+		if ret.Offsets[i], err = binary.ReadVarint(r); err != nil {
+			return nil, New("invalid encoded stack: truncated frames")
+		}
+	}
+	return ret, nil
+}
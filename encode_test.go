@@ -0,0 +1,50 @@
+package goerr
+
+import (
+	"debug/elf"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yushengji/goerr/internal/buildid"
+)
+
+type TestEncodeSuite struct {
+	suite.Suite
+	err error
+}
+
+func (s *TestEncodeSuite) SetupTest() {
+	s.err = Wrap(New("origin error"), "encode error")
+}
+
+func (s *TestEncodeSuite) TestRoundTrip() {
+	exe, _ := os.Executable()
+	if f, err := elf.Open(exe); err != nil {
+		s.T().Skip("test binary is not ELF")
+	} else {
+		f.Close()
+	}
+
+	encoded := EncodeStack(s.err)
+	stack, err := DecodeStack(encoded)
+	s.NoError(err)
+	s.Equal("encode error", stack.Message)
+	s.Len(stack.Offsets, len(stackOf(s.err)))
+	s.Equal(buildid.Read(exe), stack.BuildID)
+	s.NotEmpty(stack.BuildID)
+}
+
+func (s *TestEncodeSuite) TestInvalid() {
+	s.Empty(EncodeStack(nil))
+	_, err := DecodeStack("invalid")
+	s.Error(err)
+	_, err = DecodeStack(encodedPrefix + "!!")
+	s.Error(err)
+	_, err = DecodeStack(encodedPrefix + "Bw")
+	s.Error(err)
+}
+
+func TestEncode(t *testing.T) {
+	suite.Run(t, &TestEncodeSuite{})
+}
@@ -0,0 +1,160 @@
+package goerr
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// Property 错误码错误中可以放入响应体的属性
+type Property string
+
+const (
+	// PropHttpCode HTTP码
+	PropHttpCode Property = "httpCode"
+	// PropBusinessCode 业务码
+	PropBusinessCode Property = "businessCode"
+	// PropMessage 错误信息
+	PropMessage Property = "message"
+	// PropFields 字段校验失败信息，没有时不输出
+	PropFields Property = "fields"
+	// PropRequestID 请求ID，仅HTTP错误响应中存在
+	PropRequestID Property = "requestId"
+)
+
+// Envelope 声明式的错误响应体模板
+// 键为字段名；值为 Property 时替换为错误码错误对应的属性，属性不存在时不输出该字段；
+// 值为 Envelope 或 map[string]any 时表示嵌套的对象；其余值作为固定值原样输出。例如：
+//
+//	goerr.Envelope{"code": goerr.PropBusinessCode, "msg": goerr.PropMessage, "data": nil, "traceId": goerr.PropRequestID}
+//	goerr.Envelope{"error": goerr.Envelope{"status": goerr.PropHttpCode, "reason": goerr.PropMessage}}
+type Envelope map[string]any
+
+var envelope atomic.Pointer[Envelope]
+
+// SetEnvelope 设置错误响应体模板，作用于 ParseCode 结果的JSON序列化以及 httperr 的错误响应，
+// 模板会被复制，设置后修改传入的模板不会生效，设置为nil时恢复默认的结构
+func SetEnvelope(e Envelope) {
+	if e == nil {
+		envelope.Store(nil)
+		return
+	}
+	e = e.clone()
+	envelope.Store(&e)
+}
+
+// GetEnvelope 获取 SetEnvelope 设置的错误响应体模板，未设置时返回nil，返回的模板不应被修改
+func GetEnvelope() Envelope {
+	if e := envelope.Load(); e != nil {
+		return *e
+	}
+	return nil
+}
+
+// clone 深复制模板及其中嵌套的对象
+func (e Envelope) clone() Envelope {
+	out := make(Envelope, len(e))
+	for k, v := range e {
+		switch v := v.(type) {
+		case Envelope:
+			out[k] = v.clone()
+		case map[string]any:
+			out[k] = map[string]any(Envelope(v).clone())
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Render 使用属性值渲染模板
+func (e Envelope) Render(values map[Property]any) map[string]any {
+	out := make(map[string]any, len(e))
+	for k, v := range e {
+		switch v := v.(type) {
+		case Property:
+			if value, ok := values[v]; ok {
+				out[k] = value
+			}
+		case Envelope:
+			out[k] = v.Render(values)
+		case map[string]any:
+			out[k] = Envelope(v).Render(values)
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Extract 按照模板从响应体中提取属性值，是 Render 的逆过程
+// 数字属性会被还原为int，字段校验失败信息会被还原为 []FieldViolation
+func (e Envelope) Extract(doc map[string]any) map[Property]any {
+	values := make(map[Property]any)
+	e.extract(doc, values)
+	return values
+}
+
+func (e Envelope) extract(doc map[string]any, values map[Property]any) {
+	for k, v := range e {
+		value, ok := doc[k]
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case Property:
+			values[v] = normalize(v, value)
+		case Envelope:
+			if nested, ok := value.(map[string]any); ok {
+				v.extract(nested, values)
+			}
+		case map[string]any:
+			if nested, ok := value.(map[string]any); ok {
+				Envelope(v).extract(nested, values)
+			}
+		}
+	}
+}
+
+// normalize 将JSON解码得到的属性值还原为原始类型
+func normalize(p Property, value any) any {
+	switch p {
+	case PropHttpCode, PropBusinessCode:
+		if f, ok := value.(float64); ok {
+			return int(f)
+		}
+	case PropFields:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+		var fields []FieldViolation
+		if json.Unmarshal(data, &fields) != nil {
+			return nil
+		}
+		return fields
+	}
+	return value
+}
+
+// values 错误码错误自身的属性值
+func (w *withCode) values() map[Property]any {
+	values := map[Property]any{
+		PropHttpCode:     w.HttpCode,
+		PropBusinessCode: w.BusinessCode,
+		PropMessage:      w.Msg,
+	}
+	if len(w.Fields) > 0 {
+		values[PropFields] = w.Fields
+	}
+	return values
+}
+
+// MarshalJSON 设置了 SetEnvelope 时按照模板序列化，否则使用默认的结构
+func (w *withCode) MarshalJSON() ([]byte, error) {
+	template := GetEnvelope()
+	if template == nil {
+		type plain withCode
+		return json.Marshal((*plain)(w))
+	}
+	return json.Marshal(template.Render(w.values()))
+}
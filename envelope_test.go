@@ -0,0 +1,66 @@
+package goerr
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestEnvelopeSuite struct {
+	suite.Suite
+	mobile, admin Envelope
+	err           error
+}
+
+func (s *TestEnvelopeSuite) SetupTest() {
+	NewNotFound(5001, "order not found")
+	s.mobile = Envelope{"code": PropBusinessCode, "msg": PropMessage, "data": nil, "traceId": PropRequestID}
+	s.admin = Envelope{"error": Envelope{"status": PropHttpCode, "reason": PropMessage, "fields": PropFields}, "api": "admin"}
+	v := NewValidation().AddMessage("id", "exists", "order 7 does not exist", nil)
+	s.err = WithCode[int](v.Err(), 5001)
+}
+
+func (s *TestEnvelopeSuite) TearDownTest() {
+	SetEnvelope(nil)
+}
+
+func (s *TestEnvelopeSuite) TestRender() {
+	values := map[Property]any{PropBusinessCode: 5001, PropMessage: "order not found", PropRequestID: "req-1"}
+	s.Equal(map[string]any{"code": 5001, "msg": "order not found", "data": nil, "traceId": "req-1"},
+		s.mobile.Render(values))
+	s.Equal(map[string]any{"error": map[string]any{"reason": "order not found"}, "api": "admin"},
+		s.admin.Render(values))
+	s.Equal(map[string]any{"a": map[string]any{"b": 5001}},
+		Envelope{"a": map[string]any{"b": PropBusinessCode}}.Render(values))
+}
+
+func (s *TestEnvelopeSuite) TestMarshal() {
+	code := ParseCode(s.err)
+	data, err := json.Marshal(code)
+	s.NoError(err)
+	s.Contains(string(data), `"msg":"order not found","httpCode":404`)
+
+	SetEnvelope(s.admin)
+	s.Equal(s.admin, GetEnvelope())
+	s.admin["error"].(Envelope)["status"] = PropBusinessCode
+	s.Equal(PropHttpCode, GetEnvelope()["error"].(Envelope)["status"])
+	s.admin["error"].(Envelope)["status"] = PropHttpCode
+	data, err = json.Marshal(code)
+	s.NoError(err)
+	s.JSONEq(`{"api":"admin","error":{"status":404,"reason":"order not found",
+		"fields":[{"field":"id","constraint":"exists","message":"order 7 does not exist"}]}}`, string(data))
+
+	var doc map[string]any
+	s.NoError(json.Unmarshal(data, &doc))
+	values := s.admin.Extract(doc)
+	s.Equal(http.StatusNotFound, values[PropHttpCode])
+	s.Equal("order not found", values[PropMessage])
+	s.Equal([]FieldViolation{{Field: "id", Constraint: "exists", Message: "order 7 does not exist"}}, values[PropFields])
+	s.NotContains(values, PropBusinessCode)
+}
+
+func TestEnvelope(t *testing.T) {
+	suite.Run(t, &TestEnvelopeSuite{})
+}
@@ -3,11 +3,15 @@ package goerr
 import (
 	"fmt"
 	"io"
+	"time"
 )
 
 type fundamental struct {
 	msg string
 	*stack
+	goroutine *Goroutine
+	// decoded 通过 UnmarshalJSON 还原时的栈帧
+	decoded *decodedStack
 }
 
 func (f *fundamental) Error() string { return f.msg }
@@ -16,8 +20,7 @@ func (f *fundamental) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			io.WriteString(s, f.msg)
-			f.stack.Format(s, verb)
+			printVerbose(s, f)
 			return
 		}
 		fallthrough
@@ -31,6 +34,9 @@ func (f *fundamental) Format(s fmt.State, verb rune) {
 type withStack struct {
 	error
 	*stack
+	goroutine *Goroutine
+	// decoded 通过 UnmarshalJSON 还原时的栈帧
+	decoded *decodedStack
 }
 
 func (w *withStack) Cause() error { return w.error }
@@ -41,10 +47,7 @@ func (w *withStack) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			if w.Cause() != nil {
-				fmt.Fprintf(s, "%+v", w.Cause())
-			}
-			w.stack.Format(s, verb)
+			printVerbose(s, w)
 			return
 		}
 		fallthrough
@@ -58,19 +61,24 @@ func (w *withStack) Format(s fmt.State, verb rune) {
 type withMessage struct {
 	cause error
 	msg   string
+	// wrapped msg是否已通过 %w 包含了原因错误的信息
+	wrapped bool
+}
+
+func (w *withMessage) Error() string {
+	if loadMessageMode() == MessageOuter {
+		return w.msg
+	}
+	return outerMsg(w)
 }
 
-func (w *withMessage) Error() string { return w.msg }
 func (w *withMessage) Cause() error  { return w.cause }
 func (w *withMessage) Unwrap() error { return w.cause }
 func (w *withMessage) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			io.WriteString(s, w.msg+"\n")
-			if w.Cause() != nil {
-				fmt.Fprintf(s, "%+v", w.Cause())
-			}
+			printVerbose(s, w)
 			return
 		}
 		fallthrough
@@ -83,22 +91,35 @@ func (w *withMessage) Format(s fmt.State, verb rune) {
 
 type withCode struct {
 	cause        error
-	Msg          string `json:"msg"`
-	HttpCode     int    `json:"httpCode"`
-	BusinessCode int    `json:"businessCode"`
+	Msg          string           `json:"msg"`
+	HttpCode     int              `json:"httpCode"`
+	BusinessCode int              `json:"businessCode"`
+	Fields       []FieldViolation `json:"fields,omitempty"`
+	retryAfter   time.Duration
+	rateLimit    *RateLimit
+	// labels 通过 WithLabels 设置，创建协程信息时写入
+	labels map[string]string
+}
+
+func (w *withCode) Error() string {
+	if loadMessageMode() == MessageOuter {
+		return w.Msg
+	}
+	return outerMsg(w)
 }
 
-func (w *withCode) Error() string { return w.Msg }
 func (w *withCode) Cause() error  { return w.cause }
 func (w *withCode) Unwrap() error { return w.cause }
 func (w *withCode) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			io.WriteString(s, w.Msg+"\n")
-			if w.Cause() != nil {
-				fmt.Fprintf(s, "%+v", w.Cause())
-			}
+			printVerbose(s, w)
+			return
+		}
+		if s.Flag('#') {
+			fmt.Fprintf(s, "&goerr.withCode{Msg:%q, HttpCode:%d, BusinessCode:%d, Fields:%#v}",
+				w.Msg, w.HttpCode, w.BusinessCode, w.Fields)
 			return
 		}
 		fallthrough
@@ -1,10 +1,13 @@
 package goerr
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -75,3 +78,39 @@ func (s *TestErrorsSuite) TestCode() {
 func TestErrors(t *testing.T) {
 	suite.Run(t, &TestErrorsSuite{})
 }
+
+func TestSharedStack(t *testing.T) {
+	err := WithCode[int](WithStack(errors.New("origin")), ErrBasic)
+	outer := &withStack{error: err, stack: callers()}
+	out := fmt.Sprintf("%+v", outer)
+	assert.Contains(t, out, "more")
+	assert.Equal(t, 1, strings.Count(out, "testing.tRunner"))
+
+	SetStackOptions(WithFullStacks())
+	defer SetStackOptions()
+	out = fmt.Sprintf("%+v", outer)
+	assert.NotContains(t, out, "more")
+	assert.Equal(t, 2, strings.Count(out, "testing.tRunner"))
+}
+
+func TestCodeVerbose(t *testing.T) {
+	err := &withCode{
+		cause:        errors.New("origin"),
+		Msg:          "not found",
+		HttpCode:     http.StatusNotFound,
+		BusinessCode: 1010121,
+	}
+	assert.Equal(t, "not found [biz=1010121 http=404]\norigin", fmt.Sprintf("%+v", err))
+
+	SetCodeHeader("code={biz} status={http} msg={msg}")
+	defer SetCodeHeader("{msg} [biz={biz} http={http}]")
+	assert.Equal(t, "code=1010121 status=404 msg=not found\norigin", fmt.Sprintf("%+v", err))
+
+	WithFields(FieldViolation{Field: "id", Constraint: "exists", Message: "id not exists", Value: 7})(err)
+	assert.Equal(t, "code=1010121 status=404 msg=not found\n  - id: id not exists (exists, rejected 7)\norigin",
+		fmt.Sprintf("%+v", err))
+	assert.Equal(t, `&goerr.withCode{Msg:"not found", HttpCode:404, BusinessCode:1010121, `+
+		`Fields:[]goerr.FieldViolation{goerr.FieldViolation{Field:"id", Constraint:"exists", Message:"id not exists", Value:7}}}`,
+		fmt.Sprintf("%#v", err))
+	assert.Equal(t, err.Fields, ParseCode(Wrap(err, "wrap")).Fields)
+}
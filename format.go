@@ -0,0 +1,136 @@
+package goerr
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// printer 以 %+v 格式输出错误链
+// 所有错误类型的 %+v 输出都经由 printer 完成，从而在整条错误链上检测循环引用并限制深度，
+// 遇到循环或超过深度时输出标记代替剩余的错误链
+type printer struct {
+	w     io.Writer
+	guard *chainGuard
+}
+
+// printVerbose 以 %+v 格式输出err
+func printVerbose(w io.Writer, err error) {
+	(&printer{w: w, guard: &chainGuard{}}).print(err)
+}
+
+func (p *printer) print(err error) {
+	mark := p.guard.mark()
+	if state := p.guard.enter(err); state != chainOK {
+		io.WriteString(p.w, state.marker())
+		return
+	}
+	defer p.guard.reset(mark)
+
+	switch e := err.(type) {
+	case *fundamental:
+		io.WriteString(p.w, e.msg)
+		e.goroutine.format(p.w)
+		writeFrames(p.w, *e.stack, loadStackConfig())
+		e.decoded.format(p.w)
+	case *withStack:
+		if e.error != nil {
+			p.print(e.error)
+		}
+		e.goroutine.format(p.w)
+		e.stack.writeShared(p.w, stackOf(e.error))
+		e.decoded.format(p.w)
+	case *withMessage:
+		io.WriteString(p.w, e.msg+"\n")
+		if e.cause != nil {
+			p.print(e.cause)
+		}
+	case *withCode:
+		io.WriteString(p.w, e.header())
+		writeFields(p.w, e.Fields)
+		io.WriteString(p.w, "\n")
+		if e.cause != nil {
+			p.print(e.cause)
+		}
+	case *multiError:
+		io.WriteString(p.w, strconv.Itoa(len(e.errs))+" errors occurred:")
+		for _, branch := range e.errs {
+			p.branch("", branch)
+		}
+	case *BatchError:
+		io.WriteString(p.w, e.Error()+":")
+		for _, item := range e.Failed() {
+			label := "[" + strconv.Itoa(item.Index) + "] "
+			if item.Index < 0 {
+				label = "[" + strconv.Quote(item.Key) + "] "
+			}
+			p.branch(label, item.Err)
+		}
+	case *ValidationError:
+		io.WriteString(p.w, "validation failed:")
+		writeFields(p.w, e.violations)
+	case *decodedError:
+		io.WriteString(p.w, e.msg)
+		e.decoded.format(p.w)
+	case *decodedJoin:
+		io.WriteString(p.w, e.msg)
+		e.decoded.format(p.w)
+	default:
+		// 携带堆栈的第三方错误（如 pkg/errors）输出其错误信息，
+		// 并按照堆栈输出配置统一输出其堆栈，使混合的错误链只有一份连贯的堆栈
+		if pcs := stackOf(err); pcs != nil {
+			io.WriteString(p.w, err.Error())
+			writeFrames(p.w, pcs, loadStackConfig())
+			return
+		}
+		fmt.Fprintf(p.w, "%+v", err)
+	}
+}
+
+// codeHeader %+v 输出中错误码错误首行的模板
+var codeHeader atomic.Pointer[string]
+
+func init() {
+	SetCodeHeader("{msg} [biz={biz} http={http}]")
+}
+
+// SetCodeHeader 设置 %+v 输出中错误码错误首行的模板，便于日志解析提取错误码
+// 模板中 {msg} 替换为错误信息，{biz} 替换为业务码，{http} 替换为HTTP码，
+// 默认为 {msg} [biz={biz} http={http}]
+func SetCodeHeader(template string) {
+	codeHeader.Store(&template)
+}
+
+func (w *withCode) header() string {
+	return strings.NewReplacer(
+		"{msg}", w.Msg,
+		"{biz}", strconv.Itoa(w.BusinessCode),
+		"{http}", strconv.Itoa(w.HttpCode),
+	).Replace(*codeHeader.Load())
+}
+
+// writeFields 逐行输出字段校验失败信息
+func writeFields(w io.Writer, fields []FieldViolation) {
+	for _, f := range fields {
+		fmt.Fprintf(w, "\n  - %s: %s (%s", f.Field, f.Message, f.Constraint)
+		if f.Value != nil {
+			fmt.Fprintf(w, ", rejected %#v", f.Value)
+		}
+		io.WriteString(w, ")")
+	}
+}
+
+// branch 以缩进的形式输出分支，label不为空时输出在首行
+func (p *printer) branch(label string, err error) {
+	var b strings.Builder
+	(&printer{w: &b, guard: p.guard}).print(err)
+	for i, line := range strings.Split(b.String(), "\n") {
+		if i == 0 {
+			io.WriteString(p.w, "\n  - "+label+line)
+		} else {
+			io.WriteString(p.w, "\n    "+line)
+		}
+	}
+}
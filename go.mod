@@ -3,6 +3,7 @@ module github.com/yushengji/goerr
 go 1.24.0
 
 require (
+	github.com/pkg/errors v0.9.1
 	github.com/puzpuzpuz/xsync v1.5.2
 	github.com/stretchr/testify v1.11.1
 )
@@ -0,0 +1,137 @@
+package goerr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Goroutine 错误产生时所在协程的信息
+// 需要通过 SetStackOptions(WithGoroutine()) 开启记录
+type Goroutine struct {
+	// ID 协程ID
+	ID int64 `json:"id"`
+	// Labels 通过 WithLabels 从上下文中获取的 pprof 标签
+	Labels map[string]string `json:"labels,omitempty"`
+	// Time 错误产生的时间
+	Time time.Time `json:"time"`
+}
+
+// WithGoroutine 在捕获堆栈时同时记录协程ID与错误产生时间
+func WithGoroutine() StackOption {
+	return func(c *stackConfig) {
+		c.goroutine = true
+	}
+}
+
+// WithLabels 记录上下文中通过 pprof.Do 或 pprof.WithLabels 设置的标签
+// 标签仅写入本次创建的协程信息，原因错误已记录协程信息时不会修改
+func WithLabels(ctx context.Context) Option {
+	return func(w *withCode) {
+		w.labels = contextLabels(ctx)
+	}
+}
+
+// GoroutineOf 获取错误链中最内层记录的协程信息，即错误最初产生时所在的协程
+func GoroutineOf(err error) (*Goroutine, bool) {
+	var ret *Goroutine
+	unwrapChain(err, func(e error) bool {
+		switch e := e.(type) {
+		case *fundamental:
+			if e.goroutine != nil {
+				ret = e.goroutine
+			}
+		case *withStack:
+			if e.goroutine != nil {
+				ret = e.goroutine
+			}
+		}
+		return true
+	})
+	return ret, ret != nil
+}
+
+// String 格式为：goroutine 12 {worker="3"} at 2006-01-02T15:04:05.000Z07:00
+func (g *Goroutine) String() string {
+	var b strings.Builder
+	b.WriteString("goroutine ")
+	b.WriteString(strconv.FormatInt(g.ID, 10))
+	if len(g.Labels) > 0 {
+		keys := make([]string, 0, len(g.Labels))
+		for k := range g.Labels {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		b.WriteString(" {")
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%s=%q", k, g.Labels[k])
+		}
+		b.WriteString("}")
+	}
+	b.WriteString(" at ")
+	b.WriteString(g.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+	return b.String()
+}
+
+// format 在 %+v 输出中单独占一行，规范化输出时不输出
+func (g *Goroutine) format(w io.Writer) {
+	if g == nil || loadStackConfig().normalize {
+		return
+	}
+	io.WriteString(w, "\n")
+	io.WriteString(w, g.String())
+}
+
+// currentGoroutine 开启记录时获取当前协程信息，否则返回nil
+func currentGoroutine() *Goroutine {
+	if !loadStackConfig().goroutine {
+		return nil
+	}
+	return &Goroutine{
+		ID:   goroutineID(),
+		Time: time.Now(),
+	}
+}
+
+// goroutineID 从当前协程的堆栈首行 "goroutine 12 [running]:" 中解析协程ID
+func goroutineID() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseInt(string(b), 10, 64)
+	return id
+}
+
+// contextLabels 获取上下文中的 pprof 标签
+func contextLabels(ctx context.Context) map[string]string {
+	var labels map[string]string
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = value
+		return true
+	})
+	return labels
+}
+
+// labeled 为新创建的协程信息设置标签，未开启记录时返回nil
+func (g *Goroutine) labeled(labels map[string]string) *Goroutine {
+	if g != nil && len(labels) > 0 {
+		g.Labels = labels
+	}
+	return g
+}
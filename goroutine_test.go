@@ -0,0 +1,86 @@
+package goerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestGoroutineSuite struct {
+	suite.Suite
+}
+
+func (s *TestGoroutineSuite) SetupTest() {
+	SetStackOptions(WithGoroutine())
+}
+
+func (s *TestGoroutineSuite) TearDownTest() {
+	SetStackOptions()
+}
+
+func (s *TestGoroutineSuite) TestDisabled() {
+	SetStackOptions()
+	_, ok := GoroutineOf(New("new error"))
+	s.False(ok)
+}
+
+func (s *TestGoroutineSuite) TestCapture() {
+	before := time.Now()
+	g, ok := GoroutineOf(Wrap(New("new error"), "wrap error"))
+	s.True(ok)
+	s.Equal(goroutineID(), g.ID)
+	s.Positive(g.ID)
+	s.Nil(g.Labels)
+	s.False(g.Time.Before(before))
+}
+
+func (s *TestGoroutineSuite) TestLabels() {
+	var err, wrapped, existing error
+	pprof.Do(context.Background(), pprof.Labels("worker", "3"), func(ctx context.Context) {
+		err = WithCode[int](nil, ErrBasic, WithLabels(ctx))
+		wrapped = WithCode[int](errors.New("std error"), ErrBasic, WithLabels(ctx))
+		existing = WithCode[int](New("new error"), ErrBasic, WithLabels(ctx))
+	})
+
+	g, ok := GoroutineOf(err)
+	s.True(ok)
+	s.Equal(map[string]string{"worker": "3"}, g.Labels)
+	out := fmt.Sprintf("%+v", err)
+	s.Contains(out, fmt.Sprintf("\ngoroutine %d {worker=\"3\"} at ", g.ID))
+
+	g, _ = GoroutineOf(wrapped)
+	s.Equal(map[string]string{"worker": "3"}, g.Labels)
+	g, _ = GoroutineOf(existing)
+	s.Nil(g.Labels)
+
+	SetStackOptions()
+	s.Nil(contextLabels(context.Background()))
+	_, ok = GoroutineOf(WithCode[int](nil, ErrBasic, WithLabels(pprof.WithLabels(context.Background(), pprof.Labels("k", "v")))))
+	s.False(ok)
+}
+
+func (s *TestGoroutineSuite) TestInnermost() {
+	done := make(chan error)
+	go func() {
+		done <- New("new error")
+	}()
+	inner := <-done
+	outer := WithStack(fmt.Errorf("wrap: %s", inner))
+
+	g, ok := GoroutineOf(WithCode[int](inner, ErrBasic))
+	s.True(ok)
+	s.NotEqual(goroutineID(), g.ID)
+
+	g, ok = GoroutineOf(outer)
+	s.True(ok)
+	s.Equal(goroutineID(), g.ID)
+}
+
+func TestGoroutine(t *testing.T) {
+	suite.Run(t, &TestGoroutineSuite{})
+}
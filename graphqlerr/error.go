@@ -0,0 +1,149 @@
+// Package graphqlerr 在 goerr 错误码错误与 GraphQL 错误之间相互转换
+//
+// 错误信息按照 goerr.SetMessagePolicy 设置的策略选取，与HTTP、gRPC等协议保持一致，
+// 设置 Localizer 后优先使用按语言解析的错误码信息
+package graphqlerr
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/yushengji/goerr"
+)
+
+// Localizer 根据语言获取业务码对应的错误信息，返回空字符串时使用默认的错误信息
+type Localizer func(locale string, businessCode int) string
+
+var localizer atomic.Pointer[Localizer]
+
+// SetLocalizer 设置错误信息的本地化方式，传入nil时不进行本地化
+func SetLocalizer(l Localizer) {
+	localizer.Store(&l)
+}
+
+// Error GraphQL 响应中 errors 列表的错误
+type Error struct {
+	// Message 可以对外暴露的错误信息
+	Message string `json:"message"`
+	// Path 发生错误的字段路径，由字段名与列表下标组成
+	Path []any `json:"path,omitempty"`
+	// Extensions 错误码错误的附加信息
+	Extensions *Extensions `json:"extensions,omitempty"`
+}
+
+// Extensions 错误的附加信息
+type Extensions struct {
+	// Code 由HTTP码推导的错误类型，例如 BAD_USER_INPUT、NOT_FOUND
+	Code string `json:"code"`
+	// BusinessCode 业务码
+	BusinessCode int `json:"businessCode"`
+	// HttpCode HTTP码
+	HttpCode int `json:"httpCode"`
+	// Fields 字段校验失败信息
+	Fields []goerr.FieldViolation `json:"fields,omitempty"`
+}
+
+// Option 错误转换配置项
+type Option func(*options)
+
+type options struct {
+	path   []any
+	locale string
+}
+
+// WithPath 设置发生错误的字段路径
+func WithPath(path ...any) Option {
+	return func(o *options) {
+		o.path = path
+	}
+}
+
+// WithLocale 设置解析错误信息时使用的语言
+func WithLocale(locale string) Option {
+	return func(o *options) {
+		o.locale = locale
+	}
+}
+
+// NewError 将错误转换为 GraphQL 错误，err为nil时返回nil
+func NewError(err error, opts ...Option) *Error {
+	if err == nil {
+		return nil
+	}
+	var o options
+	for _, option := range opts {
+		option(&o)
+	}
+	code := goerr.ParseCode(err)
+	if code.HttpCode < 400 {
+		// 未携带错误码的错误按服务端错误处理，与HTTP错误响应一致
+		code.HttpCode = http.StatusInternalServerError
+	}
+	ret := &Error{
+		Message: goerr.PublicMessage(err),
+		Path:    o.path,
+		Extensions: &Extensions{
+			Code:         codeOf(code.HttpCode),
+			BusinessCode: code.BusinessCode,
+			HttpCode:     code.HttpCode,
+			Fields:       code.Fields,
+		},
+	}
+	if l := localizer.Load(); l != nil && *l != nil {
+		if msg := (*l)(o.locale, code.BusinessCode); msg != "" {
+			ret.Message = msg
+		}
+	}
+	return ret
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Err 将 GraphQL 错误还原为错误码错误，可以使用 goerr.IsCode 与 goerr.ParseCode 解析
+// 缺少附加信息时HTTP码由错误类型推导
+func (e *Error) Err() error {
+	code := goerr.ErrCode{
+		HttpCode: http.StatusInternalServerError,
+		Message:  e.Message,
+	}
+	var options []goerr.Option
+	if x := e.Extensions; x != nil {
+		code.HttpCode, code.BusinessCode = x.HttpCode, x.BusinessCode
+		if code.HttpCode == 0 {
+			code.HttpCode = httpCode(x.Code)
+		}
+		options = append(options, goerr.WithFields(x.Fields...))
+	}
+	return goerr.FromCode(nil, code, options...)
+}
+
+var codes = map[int]string{
+	http.StatusBadRequest:      "BAD_USER_INPUT",
+	http.StatusUnauthorized:    "UNAUTHENTICATED",
+	http.StatusForbidden:       "FORBIDDEN",
+	http.StatusNotFound:        "NOT_FOUND",
+	http.StatusConflict:        "CONFLICT",
+	http.StatusTooManyRequests: "TOO_MANY_REQUESTS",
+}
+
+func codeOf(httpCode int) string {
+	if c, ok := codes[httpCode]; ok {
+		return c
+	}
+	if httpCode >= 400 && httpCode < 500 {
+		return "BAD_REQUEST"
+	}
+	return "INTERNAL_SERVER_ERROR"
+}
+
+func httpCode(code string) int {
+	for h, c := range codes {
+		if c == code {
+			return h
+		}
+	}
+	if code == "BAD_REQUEST" {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,72 @@
+package graphqlerr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yushengji/goerr"
+)
+
+type TestErrorSuite struct {
+	suite.Suite
+	notFoundErr, internalErr error
+}
+
+func (s *TestErrorSuite) SetupTest() {
+	goerr.NewNotFound(4901, "order not found")
+	goerr.NewInternalError(4902, "order service error")
+	s.notFoundErr = goerr.WithCode[int](nil, 4901, goerr.WithMessage("order 7 not found"))
+	s.internalErr = goerr.WithCode[int](errors.New("connection refused"), 4902, goerr.WithMessage("dial 10.0.0.1 failed"))
+}
+
+func (s *TestErrorSuite) TearDownTest() {
+	SetLocalizer(nil)
+}
+
+func (s *TestErrorSuite) TestNewError() {
+	data, err := json.Marshal(NewError(s.notFoundErr, WithPath("orders", 0, "detail")))
+	s.NoError(err)
+	s.JSONEq(`{"message":"order 7 not found","path":["orders",0,"detail"],
+		"extensions":{"code":"NOT_FOUND","businessCode":4901,"httpCode":404}}`, string(data))
+
+	e := NewError(s.internalErr)
+	s.Equal("order service error", e.Message)
+	s.Equal("INTERNAL_SERVER_ERROR", e.Extensions.Code)
+	s.Nil(NewError(nil))
+
+	e = NewError(errors.New("db down"))
+	s.Equal("INTERNAL_SERVER_ERROR", e.Extensions.Code)
+	s.Equal(http.StatusInternalServerError, e.Extensions.HttpCode)
+	s.Equal(http.StatusText(http.StatusInternalServerError), e.Message)
+
+	SetLocalizer(func(locale string, businessCode int) string {
+		if locale == "zh-CN" && businessCode == 4901 {
+			return "订单不存在"
+		}
+		return ""
+	})
+	s.Equal("订单不存在", NewError(s.notFoundErr, WithLocale("zh-CN")).Message)
+	s.Equal("order 7 not found", NewError(s.notFoundErr, WithLocale("en")).Message)
+}
+
+func (s *TestErrorSuite) TestErr() {
+	var e Error
+	data, _ := json.Marshal(NewError(s.notFoundErr))
+	s.NoError(json.Unmarshal(data, &e))
+	err := e.Err()
+	s.True(goerr.IsCode(err, 4901))
+	s.Equal(http.StatusNotFound, goerr.ParseCode(err).HttpCode)
+	s.Equal("order 7 not found", err.Error())
+
+	err = (&Error{Message: "forbidden", Extensions: &Extensions{Code: "FORBIDDEN"}}).Err()
+	s.Equal(http.StatusForbidden, goerr.ParseCode(err).HttpCode)
+	err = (&Error{Message: "boom"}).Err()
+	s.Equal(http.StatusInternalServerError, goerr.ParseCode(err).HttpCode)
+}
+
+func TestError(t *testing.T) {
+	suite.Run(t, &TestErrorSuite{})
+}
@@ -0,0 +1,93 @@
+// Package grpcerr 在 goerr 错误码错误与 gRPC 状态之间相互转换
+//
+// gRPC 状态码默认由错误码的HTTP码推导，可以使用 Register 为单个错误码指定，
+// 业务码、HTTP码通过 ErrorInfo 传递，字段校验失败信息通过 BadRequest 传递，重试时间通过 RetryInfo 传递
+package grpcerr
+
+import (
+	"net/http"
+
+	"github.com/puzpuzpuz/xsync"
+	"github.com/yushengji/goerr"
+	"google.golang.org/grpc/codes"
+)
+
+var codeMap = xsync.NewIntegerMapOf[int, codes.Code]()
+
+// Register 为错误码指定 gRPC 状态码，返回传入的错误码，便于在注册错误码时使用：
+//
+//	var ErrStockConflict = grpcerr.Register(goerr.NewConflict(21, "stock conflict"), codes.Aborted)
+func Register(code goerr.ErrCode, grpcCode codes.Code) goerr.ErrCode {
+	codeMap.Store(code.BusinessCode, grpcCode)
+	return code
+}
+
+// Code 获取业务码对应的 gRPC 状态码，未通过 Register 指定时由HTTP码推导
+func Code(businessCode, httpCode int) codes.Code {
+	if c, ok := codeMap.Load(businessCode); ok {
+		return c
+	}
+	return fromHTTP(httpCode)
+}
+
+// fromHTTP 由HTTP码推导 gRPC 状态码，2xx等非错误响应码推导为 codes.Unknown
+func fromHTTP(httpCode int) codes.Code {
+	switch httpCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return codes.NotFound
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	switch {
+	case httpCode >= 400 && httpCode < 500:
+		return codes.FailedPrecondition
+	case httpCode >= 500 && httpCode < 600:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// toHTTP 由 gRPC 状态码推导HTTP码，用于还原未携带 ErrorInfo 的状态
+func toHTTP(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,22 @@
+module github.com/yushengji/goerr/grpcerr
+
+go 1.24.0
+
+require (
+	github.com/puzpuzpuz/xsync v1.5.2
+	github.com/stretchr/testify v1.11.1
+	github.com/yushengji/goerr v0.0.0-20261019180445-6267c8ed0c39
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251202230838-ff82c1b0f217
+	google.golang.org/grpc v1.79.1
+	google.golang.org/protobuf v1.36.10
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.48.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/text v0.32.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
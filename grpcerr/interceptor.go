@@ -0,0 +1,75 @@
+package grpcerr
+
+import (
+	"context"
+	"io"
+
+	"github.com/yushengji/goerr"
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptor 将处理函数返回的错误转换为 gRPC 状态，
+// 处理时发生的panic通过 goerr.Recover 转换为错误码错误
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if err != nil {
+				err = ToStatus(err).Err()
+			}
+		}()
+		defer goerr.Recover(&err)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 将流处理函数返回的错误转换为 gRPC 状态，
+// 处理时发生的panic通过 goerr.Recover 转换为错误码错误
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if err != nil {
+				err = ToStatus(err).Err()
+			}
+		}()
+		defer goerr.Recover(&err)
+		return handler(srv, ss)
+	}
+}
+
+// UnaryClientInterceptor 将调用返回的 gRPC 状态错误还原为错误码错误
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor 将建立流以及收发消息时返回的 gRPC 状态错误还原为错误码错误
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, FromError(err)
+		}
+		return &clientStream{ClientStream: cs}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return fromStreamError(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	return fromStreamError(s.ClientStream.RecvMsg(m))
+}
+
+// fromStreamError 流正常结束时返回的 io.EOF 需要原样返回
+func fromStreamError(err error) error {
+	if err == io.EOF {
+		return err
+	}
+	return FromError(err)
+}
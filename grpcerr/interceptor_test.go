@@ -0,0 +1,105 @@
+package grpcerr
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yushengji/goerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (healthServer) Check(_ context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	switch req.GetService() {
+	case "order":
+		return nil, goerr.WithCode[int](goerr.New("order db down"), 4701)
+	case "panic":
+		var m map[string]int
+		m["a"] = 1
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}); err != nil {
+		return err
+	}
+	return goerr.WithCode[int](nil, 4702)
+}
+
+type TestInterceptorSuite struct {
+	suite.Suite
+	server *grpc.Server
+	conn   *grpc.ClientConn
+	client healthpb.HealthClient
+}
+
+func (s *TestInterceptorSuite) SetupTest() {
+	goerr.NewServiceUnavailable(4701, "order unavailable")
+	goerr.NewNotFound(4702, "watch target not found")
+
+	lis := bufconn.Listen(1 << 20)
+	s.server = grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor()),
+		grpc.StreamInterceptor(StreamServerInterceptor()),
+	)
+	healthpb.RegisterHealthServer(s.server, healthServer{})
+	go s.server.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	s.Require().NoError(err)
+	s.conn = conn
+	s.client = healthpb.NewHealthClient(conn)
+}
+
+func (s *TestInterceptorSuite) TearDownTest() {
+	s.conn.Close()
+	s.server.Stop()
+}
+
+func (s *TestInterceptorSuite) TestUnary() {
+	ctx := context.Background()
+	_, err := s.client.Check(ctx, &healthpb.HealthCheckRequest{Service: "order"})
+	s.True(goerr.IsCode(err, 4701))
+	s.Equal("order unavailable", goerr.ParseCode(err).Msg)
+	s.Equal(codes.Unavailable, status.Code(err))
+
+	_, err = s.client.Check(ctx, &healthpb.HealthCheckRequest{Service: "panic"})
+	s.True(goerr.IsCode(err, goerr.ErrBasic))
+	s.Equal(codes.Internal, status.Code(err))
+
+	resp, err := s.client.Check(ctx, &healthpb.HealthCheckRequest{})
+	s.NoError(err)
+	s.Equal(healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+func (s *TestInterceptorSuite) TestStream() {
+	stream, err := s.client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	s.Require().NoError(err)
+	_, err = stream.Recv()
+	s.NoError(err)
+	_, err = stream.Recv()
+	s.True(goerr.IsCode(err, 4702))
+	s.Equal(codes.NotFound, status.Code(err))
+}
+
+func TestInterceptor(t *testing.T) {
+	suite.Run(t, &TestInterceptorSuite{})
+}
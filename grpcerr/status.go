@@ -0,0 +1,136 @@
+package grpcerr
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/yushengji/goerr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// domain ErrorInfo 中的错误域
+var domain atomic.Pointer[string]
+
+func init() {
+	SetDomain("goerr")
+}
+
+// SetDomain 设置 ErrorInfo 中的错误域，默认为 goerr
+// 只有错误域相同的 ErrorInfo 才会被 FromStatus 解析为业务码
+func SetDomain(d string) {
+	domain.Store(&d)
+}
+
+// ToStatus 将错误转换为 gRPC 状态，err为nil时返回 codes.OK 状态
+// 错误信息按照 goerr.SetMessagePolicy 设置的策略选取，
+// 错误链中没有错误码错误但已经是 gRPC 状态错误时直接返回该状态
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if !hasCode(err) && errors.As(err, &grpcErr) {
+		return grpcErr.GRPCStatus()
+	}
+
+	code := goerr.ParseCode(err)
+	if code.HttpCode < 400 {
+		// 未携带错误码的错误按服务端错误处理，与 PublicMessage 的错误信息一致
+		code.HttpCode = http.StatusInternalServerError
+	}
+	st := status.New(Code(code.BusinessCode, code.HttpCode), goerr.PublicMessage(err))
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason: "CODE_" + strconv.Itoa(code.BusinessCode),
+		Domain: *domain.Load(),
+		Metadata: map[string]string{
+			"businessCode": strconv.Itoa(code.BusinessCode),
+			"httpCode":     strconv.Itoa(code.HttpCode),
+		},
+	}}
+	if len(code.Fields) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range code.Fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+				Reason:      f.Constraint,
+			})
+		}
+		details = append(details, br)
+	}
+	if d, ok := goerr.RetryAfter(err); ok {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(d)})
+	}
+	if withDetails, e := st.WithDetails(details...); e == nil {
+		return withDetails
+	}
+	return st
+}
+
+// FromStatus 将 gRPC 状态还原为错误码错误，codes.OK 状态返回nil
+// 携带 ErrorInfo 时使用其中的业务码与HTTP码，否则HTTP码由 gRPC 状态码推导，
+// 还原的错误可以使用 goerr.IsCode、goerr.ParseCode 解析，也可以使用 status.FromError 获取原始状态
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	code := goerr.ErrCode{
+		HttpCode: toHTTP(st.Code()),
+		Message:  st.Message(),
+	}
+	var options []goerr.Option
+	errDomain := *domain.Load()
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() != errDomain {
+				continue
+			}
+			if biz, err := strconv.Atoi(d.GetMetadata()["businessCode"]); err == nil {
+				code.BusinessCode = biz
+			}
+			if httpCode, err := strconv.Atoi(d.GetMetadata()["httpCode"]); err == nil {
+				code.HttpCode = httpCode
+			}
+		case *errdetails.BadRequest:
+			for _, f := range d.GetFieldViolations() {
+				options = append(options, goerr.WithFields(goerr.FieldViolation{
+					Field:      f.GetField(),
+					Constraint: f.GetReason(),
+					Message:    f.GetDescription(),
+				}))
+			}
+		case *errdetails.RetryInfo:
+			options = append(options, goerr.WithRetryAfter(d.GetRetryDelay().AsDuration()))
+		}
+	}
+	return goerr.FromCode(st.Err(), code, options...)
+}
+
+// FromError 将调用 gRPC 服务返回的错误还原为错误码错误，不是 gRPC 状态错误时原样返回
+func FromError(err error) error {
+	if err == nil || hasCode(err) {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return FromStatus(st)
+}
+
+// hasCode 判断错误链中是否包含错误码错误
+func hasCode(err error) bool {
+	found := false
+	goerr.Walk(err, func(layer goerr.Layer) bool {
+		found = layer.Code != nil
+		return !found
+	})
+	return found
+}
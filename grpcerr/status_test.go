@@ -0,0 +1,99 @@
+package grpcerr
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yushengji/goerr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type TestStatusSuite struct {
+	suite.Suite
+	notFoundErr, conflictErr, validationErr error
+}
+
+func (s *TestStatusSuite) SetupTest() {
+	goerr.NewNotFound(4601, "order not found")
+	Register(goerr.NewConflict(4602, "order locked"), codes.Aborted)
+	goerr.NewBadRequest(4603, "invalid order")
+	s.notFoundErr = goerr.WithCode[int](errors.New("no rows"), 4601, goerr.WithMessage("order 7 not found"))
+	s.conflictErr = goerr.WithCode[int](nil, 4602, goerr.WithRetryAfter(time.Second))
+	v := goerr.NewValidation().AddMessage("amount", "min", "amount must be positive", -1)
+	s.validationErr = goerr.WithCode[int](v.Err(), 4603)
+}
+
+func (s *TestStatusSuite) TearDownTest() {
+	SetDomain("goerr")
+}
+
+func (s *TestStatusSuite) TestToStatus() {
+	st := ToStatus(s.notFoundErr)
+	s.Equal(codes.NotFound, st.Code())
+	s.Equal("order 7 not found", st.Message())
+	s.Len(st.Details(), 1)
+
+	s.Equal(codes.Aborted, ToStatus(s.conflictErr).Code())
+	s.Len(ToStatus(s.conflictErr).Details(), 2)
+	s.Len(ToStatus(s.validationErr).Details(), 2)
+
+	s.Equal(codes.OK, ToStatus(nil).Code())
+	st = ToStatus(errors.New("db down"))
+	s.Equal(codes.Internal, st.Code())
+	s.Equal(http.StatusText(http.StatusInternalServerError), st.Message())
+	s.Equal(codes.PermissionDenied, ToStatus(status.Error(codes.PermissionDenied, "denied")).Code())
+}
+
+func (s *TestStatusSuite) TestFromStatus() {
+	err := FromStatus(ToStatus(s.notFoundErr))
+	s.True(goerr.IsCode(err, 4601))
+	code := goerr.ParseCode(err)
+	s.Equal(http.StatusNotFound, code.HttpCode)
+	s.Equal("order 7 not found", code.Msg)
+	st, ok := status.FromError(err)
+	s.True(ok)
+	s.Equal(codes.NotFound, st.Code())
+
+	err = FromError(ToStatus(s.conflictErr).Err())
+	s.True(goerr.IsCode(err, 4602))
+	d, ok := goerr.RetryAfter(err)
+	s.True(ok)
+	s.Equal(time.Second, d)
+
+	fields := goerr.ParseCode(FromStatus(ToStatus(s.validationErr))).Fields
+	s.Len(fields, 1)
+	s.Equal("amount", fields[0].Field)
+	s.Equal("min", fields[0].Constraint)
+
+	err = FromError(status.Error(codes.Unavailable, "try later"))
+	s.Equal(http.StatusServiceUnavailable, goerr.ParseCode(err).HttpCode)
+	s.Equal("try later", err.Error())
+
+	st = ToStatus(s.notFoundErr)
+	SetDomain("other")
+	code = goerr.ParseCode(FromStatus(st))
+	s.Zero(code.BusinessCode)
+	s.Equal(http.StatusNotFound, code.HttpCode)
+
+	s.NoError(FromStatus(status.New(codes.OK, "")))
+	s.Equal(s.notFoundErr, FromError(s.notFoundErr))
+	plain := errors.New("plain")
+	s.Equal(plain, FromError(plain))
+}
+
+func (s *TestStatusSuite) TestCode() {
+	s.Equal(codes.Aborted, Code(4602, http.StatusConflict))
+	s.Equal(codes.AlreadyExists, Code(0, http.StatusConflict))
+	s.Equal(codes.ResourceExhausted, Code(0, http.StatusTooManyRequests))
+	s.Equal(codes.FailedPrecondition, Code(0, http.StatusTeapot))
+	s.Equal(codes.Internal, Code(0, http.StatusBadGateway))
+	s.Equal(codes.Unknown, Code(0, http.StatusOK))
+}
+
+func TestStatus(t *testing.T) {
+	suite.Run(t, &TestStatusSuite{})
+}
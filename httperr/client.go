@@ -0,0 +1,224 @@
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yushengji/goerr"
+)
+
+// maxErrorBody 解析错误响应时最多读取的响应体长度
+const maxErrorBody = 64 << 10
+
+// RemoteError 其他服务返回的错误响应
+type RemoteError struct {
+	// Service 服务名称，未指定时为请求的主机名
+	Service string
+	// URL 请求地址，不包含查询参数
+	URL string
+	// HttpCode 响应码
+	HttpCode int
+	// BusinessCode 响应中的业务码，响应中没有业务码时为0
+	BusinessCode int
+	// Msg 响应中的错误信息
+	Msg string
+	// Fields 响应中的字段校验失败信息
+	Fields []goerr.FieldViolation
+	// RequestID 响应中的请求ID
+	RequestID string
+	// RetryAfter 响应头 Retry-After 指定的重试时间，未指定时为0
+	RetryAfter time.Duration
+	// RateLimit 响应头 RateLimit-* 指定的限流信息，未指定时为nil
+	RateLimit *goerr.RateLimit
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("%s %s: %s [biz=%d http=%d]", e.Service, e.URL, e.Msg, e.BusinessCode, e.HttpCode)
+}
+
+// Code 获取远程服务的错误码
+func (e *RemoteError) Code() goerr.ErrCode {
+	return goerr.ErrCode{HttpCode: e.HttpCode, BusinessCode: e.BusinessCode, Message: e.Msg}
+}
+
+// DecodeOption 解析错误响应的配置项
+type DecodeOption func(*decodeConfig)
+
+type decodeConfig struct {
+	template goerr.Envelope
+}
+
+// WithDecodeTemplate 按照下游服务使用的响应体模板解析JSON与XML错误响应，
+// 未设置时按照默认的 Response 结构解析，不会使用本服务写出错误响应时的模板
+func WithDecodeTemplate(template goerr.Envelope) DecodeOption {
+	return func(c *decodeConfig) {
+		c.template = template
+	}
+}
+
+// DecodeResponse 将响应码不小于400的响应转换为错误，其余响应返回nil
+// 错误以 goerr.ErrServiceInvoke 错误码包装 RemoteError，可以使用 goerr.As 获取远程服务的错误信息，
+// 错误码未注册提示信息时使用 RemoteError 的错误信息
+// 支持 WriteError 输出的JSON、XML、问题详情与纯文本格式，读取后的响应体仍可再次读取
+func DecodeResponse(resp *http.Response, options ...DecodeOption) error {
+	c := &decodeConfig{}
+	for _, option := range options {
+		option(c)
+	}
+	return c.decode(resp, "")
+}
+
+func (c *decodeConfig) decode(resp *http.Response, service string) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	remote := &RemoteError{
+		Service:  service,
+		HttpCode: resp.StatusCode,
+	}
+	if req := resp.Request; req != nil {
+		u := *req.URL
+		u.RawQuery, u.Fragment = "", ""
+		remote.URL = req.Method + " " + u.Redacted()
+		if remote.Service == "" {
+			remote.Service = u.Hostname()
+		}
+	}
+	c.parseBody(remote, resp.Header.Get("Content-Type"), data)
+	if remote.Msg == "" {
+		remote.Msg = http.StatusText(resp.StatusCode)
+	}
+
+	options := []goerr.Option{goerr.WithDefaultMessage(remote.Error())}
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		remote.RetryAfter = d
+		options = append(options, goerr.WithRetryAfter(d))
+	}
+	if limit, ok := parseRateLimit(resp.Header); ok {
+		remote.RateLimit = &limit
+		options = append(options, goerr.WithRateLimit(limit.Limit, limit.Remaining, limit.Reset))
+	}
+	return goerr.WithCode[int](remote, goerr.ErrServiceInvoke, options...)
+}
+
+// parseRetryAfter 解析秒数或HTTP日期格式的 Retry-After
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return time.Duration(n) * time.Second, n > 0
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		return d, d > 0
+	}
+	return 0, false
+}
+
+// parseRateLimit 解析 RateLimit-Limit、RateLimit-Remaining 与 RateLimit-Reset 响应头
+func parseRateLimit(h http.Header) (goerr.RateLimit, bool) {
+	limit, err := strconv.Atoi(h.Get("RateLimit-Limit"))
+	if err != nil {
+		return goerr.RateLimit{}, false
+	}
+	remaining, _ := strconv.Atoi(h.Get("RateLimit-Remaining"))
+	reset, _ := strconv.Atoi(h.Get("RateLimit-Reset"))
+	return goerr.RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Duration(reset) * time.Second,
+	}, true
+}
+
+func (c *decodeConfig) parseBody(remote *RemoteError, contentType string, data []byte) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == mimeProblem:
+		p, err := ParseProblem(data)
+		if err != nil {
+			return
+		}
+		remote.BusinessCode, remote.Fields, remote.RequestID = p.BusinessCode, p.Fields, p.RequestID
+		remote.Msg = p.Detail
+		if remote.Msg == "" {
+			remote.Msg = p.Title
+		}
+	case strings.HasSuffix(mediaType, "json"):
+		var resp Response
+		if template := c.template; template != nil {
+			var doc map[string]any
+			if json.Unmarshal(data, &doc) != nil {
+				return
+			}
+			resp.fromValues(template.Extract(doc))
+		} else if json.Unmarshal(data, &resp) != nil {
+			return
+		}
+		remote.BusinessCode, remote.Msg, remote.Fields, remote.RequestID = resp.BusinessCode, resp.Msg, resp.Fields, resp.RequestID
+	case strings.HasSuffix(mediaType, "xml"):
+		var resp Response
+		if template := c.template; template != nil {
+			var doc xmlNode
+			if xml.Unmarshal(data, &doc) != nil {
+				return
+			}
+			values := make(map[goerr.Property]any)
+			doc.extract(template, values)
+			resp.fromValues(values)
+		} else if xml.Unmarshal(data, &resp) != nil {
+			return
+		}
+		remote.BusinessCode, remote.Msg, remote.Fields, remote.RequestID = resp.BusinessCode, resp.Msg, resp.Fields, resp.RequestID
+	case strings.HasPrefix(mediaType, "text/"):
+		msg, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+		remote.Msg = msg
+	}
+}
+
+// fromValues 使用按照模板提取的属性值填充响应内容
+func (resp *Response) fromValues(values map[goerr.Property]any) {
+	resp.BusinessCode, _ = values[goerr.PropBusinessCode].(int)
+	resp.Msg, _ = values[goerr.PropMessage].(string)
+	resp.Fields, _ = values[goerr.PropFields].([]goerr.FieldViolation)
+	resp.RequestID, _ = values[goerr.PropRequestID].(string)
+}
+
+// Transport 将响应码不小于400的响应转换为错误的 http.RoundTripper，转换方式与 DecodeResponse 相同，
+// 重定向、304等响应原样返回；请求失败时返回以 goerr.ErrServiceInvoke 错误码包装的错误
+type Transport struct {
+	// Service 服务名称，为空时使用请求的主机名
+	Service string
+	// Template 下游服务使用的响应体模板，与 WithDecodeTemplate 相同
+	Template goerr.Envelope
+	// Base 实际发送请求的 http.RoundTripper，为nil时使用 http.DefaultTransport
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, goerr.WithCode[int](err, goerr.ErrServiceInvoke, goerr.WithDefaultMessage(err.Error()))
+	}
+	c := &decodeConfig{template: t.Template}
+	if err := c.decode(resp, t.Service); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,143 @@
+package httperr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yushengji/goerr"
+)
+
+type TestClientSuite struct {
+	suite.Suite
+	server *httptest.Server
+}
+
+func (s *TestClientSuite) SetupTest() {
+	goerr.NewConflict(4301, "stock conflict")
+	goerr.NewServiceUnavailable(4302, "stock busy")
+	mux := http.NewServeMux()
+	mux.Handle("/stock", Handler(func(http.ResponseWriter, *http.Request) error {
+		return goerr.WithCode[int](goerr.New("sku 1 locked"), 4301, goerr.WithMessage("sku 1 is locked"))
+	}))
+	mux.HandleFunc("/text", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "upstream timeout", http.StatusBadGateway)
+	})
+	mux.Handle("/busy", Handler(func(http.ResponseWriter, *http.Request) error {
+		return goerr.WithCode[int](nil, 4302, goerr.WithRetryAfter(time.Minute), goerr.WithRateLimit(5, 0, 30*time.Second))
+	}))
+	mux.HandleFunc("/date", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "ok")
+	})
+	mux.Handle("/old", http.RedirectHandler("/ok", http.StatusFound))
+	mux.HandleFunc("/cached", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	})
+	s.server = httptest.NewServer(mux)
+}
+
+func (s *TestClientSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *TestClientSuite) TestDecodeResponse() {
+	for _, accept := range []string{"application/json", "application/problem+json"} {
+		req, _ := http.NewRequest(http.MethodGet, s.server.URL+"/stock?token=secret", nil)
+		req.Header.Set("Accept", accept)
+		req.Header.Set("X-Request-Id", "req-1")
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		err = DecodeResponse(resp)
+		s.True(goerr.IsCode(err, goerr.ErrServiceInvoke))
+		var remote *RemoteError
+		s.Require().True(goerr.As(err, &remote))
+		s.Equal(http.StatusConflict, remote.HttpCode)
+		s.Equal(4301, remote.BusinessCode)
+		s.Equal("sku 1 is locked", remote.Msg)
+		s.Equal("req-1", remote.RequestID)
+		s.Equal("GET "+s.server.URL+"/stock", remote.URL)
+		s.Equal("127.0.0.1", remote.Service)
+		s.Equal(4301, remote.Code().BusinessCode)
+		s.Equal(remote.Error(), err.Error())
+
+		body, _ := io.ReadAll(resp.Body)
+		s.Contains(string(body), "sku 1 is locked")
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(s.server.URL + "/text")
+	s.Require().NoError(err)
+	var remote *RemoteError
+	s.True(goerr.As(DecodeResponse(resp), &remote))
+	s.Equal("upstream timeout", remote.Msg)
+	s.Zero(remote.BusinessCode)
+
+	resp, err = http.Get(s.server.URL + "/ok")
+	s.Require().NoError(err)
+	s.NoError(DecodeResponse(resp))
+	resp.Body.Close()
+}
+
+func (s *TestClientSuite) TestRetry() {
+	resp, err := http.Get(s.server.URL + "/busy")
+	s.Require().NoError(err)
+	err = DecodeResponse(resp)
+	var remote *RemoteError
+	s.Require().True(goerr.As(err, &remote))
+	s.Equal(time.Minute, remote.RetryAfter)
+	s.Equal(&goerr.RateLimit{Limit: 5, Remaining: 0, Reset: 30 * time.Second}, remote.RateLimit)
+	d, ok := goerr.RetryAfter(err)
+	s.True(ok)
+	s.Equal(time.Minute, d)
+	limit, ok := goerr.RateLimitOf(err)
+	s.True(ok)
+	s.Equal(5, limit.Limit)
+
+	resp, err = http.Get(s.server.URL + "/date")
+	s.Require().NoError(err)
+	d, ok = goerr.RetryAfter(DecodeResponse(resp))
+	s.True(ok)
+	s.InDelta(time.Hour.Seconds(), d.Seconds(), 5)
+	_, ok = goerr.RateLimitOf(DecodeResponse(resp))
+	s.False(ok)
+}
+
+func (s *TestClientSuite) TestTransport() {
+	client := &http.Client{Transport: &Transport{Service: "stock"}}
+	_, err := client.Get(s.server.URL + "/stock")
+	s.True(goerr.IsCode(err, goerr.ErrServiceInvoke))
+	var remote *RemoteError
+	s.Require().True(errors.As(err, &remote))
+	s.Equal("stock", remote.Service)
+	s.Equal(`Get "`+s.server.URL+`/stock": `+remote.Error(), err.Error())
+
+	resp, err := client.Get(s.server.URL + "/old")
+	s.Require().NoError(err)
+	body, _ := io.ReadAll(resp.Body)
+	s.Equal("ok", string(body))
+	resp.Body.Close()
+
+	resp, err = client.Get(s.server.URL + "/cached")
+	s.Require().NoError(err)
+	s.Equal(http.StatusNotModified, resp.StatusCode)
+	resp.Body.Close()
+
+	s.server.Close()
+	_, err = client.Get(s.server.URL + "/ok")
+	s.True(goerr.IsCode(err, goerr.ErrServiceInvoke))
+	s.False(goerr.As(err, &remote))
+	s.Contains(err.Error(), "connection refused")
+}
+
+func TestClient(t *testing.T) {
+	suite.Run(t, &TestClientSuite{})
+}
@@ -0,0 +1,229 @@
+package httperr
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yushengji/goerr"
+)
+
+const mimeXML = "application/xml"
+
+// Encoder 将响应体编码后写入w
+type Encoder func(w io.Writer, body any) error
+
+type mediaEncoder struct {
+	mediaType   string
+	contentType string
+	encode      Encoder
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = []mediaEncoder{
+		{mimeJSON, mimeJSON + "; charset=utf-8", encodeJSON},
+		{mimeXML, mimeXML + "; charset=utf-8", encodeXML},
+		{"text/xml", "text/xml; charset=utf-8", encodeXML},
+	}
+)
+
+// RegisterEncoder 注册媒体类型对应的编码器，例如 application/msgpack，已注册的媒体类型会被替换
+// 编码器按照注册顺序参与 Accept 协商，编码的响应体同样由 WithEnvelope 设置的方式包装，
+// 包装后的响应体无法编码时退回编码 Response
+func RegisterEncoder(mediaType string, encoder Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	e := mediaEncoder{mediaType: mediaType, contentType: mediaType, encode: encoder}
+	if i := slices.IndexFunc(encoders, func(e mediaEncoder) bool { return e.mediaType == mediaType }); i >= 0 {
+		encoders[i] = e
+		return
+	}
+	encoders = append(encoders, e)
+}
+
+func lookupEncoder(mediaType string) mediaEncoder {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	for _, e := range encoders {
+		if e.mediaType == mediaType {
+			return e
+		}
+	}
+	return encoders[0]
+}
+
+// offers 可以协商的响应体格式，排在前面的优先
+func (c *config) offers() []string {
+	encodersMu.RLock()
+	offers := make([]string, 0, len(encoders)+2)
+	for _, e := range encoders {
+		offers = append(offers, e.mediaType)
+	}
+	encodersMu.RUnlock()
+	if c.problem {
+		offers = slices.Insert(offers, 0, mimeProblem)
+	} else {
+		offers = slices.Insert(offers, 1, mimeProblem)
+	}
+	return append(offers, mimeText)
+}
+
+func encodeJSON(w io.Writer, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func encodeXML(w io.Writer, body any) error {
+	switch b := body.(type) {
+	case *Response:
+		body = b.xml()
+	case map[string]any:
+		// 模板渲染得到的响应体以键作为元素名称编码
+		body = xmlEnvelope(b)
+	}
+	data, err := xml.Marshal(body)
+	if err != nil {
+		return err
+	}
+	io.WriteString(w, xml.Header)
+	_, err = w.Write(data)
+	return err
+}
+
+// xmlResponse XML格式的 Response，没有字段校验失败信息时与JSON相同不输出 fields 元素
+type xmlResponse struct {
+	XMLName      xml.Name   `xml:"error"`
+	HttpCode     int        `xml:"httpCode"`
+	BusinessCode int        `xml:"businessCode"`
+	Msg          string     `xml:"msg"`
+	Fields       *xmlFields `xml:"fields,omitempty"`
+	RequestID    string     `xml:"requestId,omitempty"`
+}
+
+func (resp *Response) xml() *xmlResponse {
+	ret := &xmlResponse{
+		HttpCode:     resp.HttpCode,
+		BusinessCode: resp.BusinessCode,
+		Msg:          resp.Msg,
+		RequestID:    resp.RequestID,
+	}
+	if len(resp.Fields) > 0 {
+		ret.Fields = &xmlFields{Fields: resp.Fields}
+	}
+	return ret
+}
+
+// xmlEnvelope 按照模板渲染得到的响应体，根元素为 error，键作为子元素名称按字典序输出，
+// 与 Response 相同，字段校验失败信息输出为 fields 元素中的多个 field 元素
+type xmlEnvelope map[string]any
+
+func (m xmlEnvelope) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if start.Name.Local == "xmlEnvelope" {
+		start.Name = xml.Name{Local: "error"}
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if err := encodeXMLValue(e, xml.StartElement{Name: xml.Name{Local: k}}, m[k]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func encodeXMLValue(e *xml.Encoder, start xml.StartElement, value any) error {
+	switch v := value.(type) {
+	case nil:
+		return e.EncodeElement("", start)
+	case map[string]any:
+		return xmlEnvelope(v).MarshalXML(e, start)
+	case []goerr.FieldViolation:
+		return e.EncodeElement(xmlFields{Fields: v}, start)
+	}
+	return e.EncodeElement(value, start)
+}
+
+// xmlFields XML中的字段校验失败信息
+type xmlFields struct {
+	Fields []goerr.FieldViolation `xml:"field"`
+}
+
+// xmlNode 按照模板解析XML响应体时使用的通用元素
+type xmlNode struct {
+	XMLName xml.Name
+	Content string    `xml:",chardata"`
+	Nodes   []xmlNode `xml:",any"`
+}
+
+func (n *xmlNode) child(name string) *xmlNode {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == name {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// extract 按照模板从XML元素中提取属性值，与 goerr.Envelope.Extract 对应
+func (n *xmlNode) extract(template goerr.Envelope, values map[goerr.Property]any) {
+	for k, v := range template {
+		child := n.child(k)
+		if child == nil {
+			continue
+		}
+		switch v := v.(type) {
+		case goerr.Property:
+			values[v] = child.value(v)
+		case goerr.Envelope:
+			child.extract(v, values)
+		case map[string]any:
+			child.extract(v, values)
+		}
+	}
+}
+
+// value 将元素还原为属性的原始类型
+func (n *xmlNode) value(p goerr.Property) any {
+	content := strings.TrimSpace(n.Content)
+	switch p {
+	case goerr.PropHttpCode, goerr.PropBusinessCode:
+		code, _ := strconv.Atoi(content)
+		return code
+	case goerr.PropFields:
+		var fields []goerr.FieldViolation
+		for _, f := range n.Nodes {
+			fields = append(fields, goerr.FieldViolation{
+				Field:      f.text("field"),
+				Constraint: f.text("constraint"),
+				Message:    f.text("message"),
+			})
+			if c := f.child("rejectedValue"); c != nil {
+				fields[len(fields)-1].Value = c.Content
+			}
+		}
+		return fields
+	}
+	return content
+}
+
+func (n *xmlNode) text(name string) string {
+	if c := n.child(name); c != nil {
+		return c.Content
+	}
+	return ""
+}
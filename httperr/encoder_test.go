@@ -0,0 +1,128 @@
+package httperr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yushengji/goerr"
+)
+
+type TestEncoderSuite struct {
+	suite.Suite
+	err error
+}
+
+func (s *TestEncoderSuite) SetupTest() {
+	goerr.NewBadRequest(4901, "invalid order")
+	v := goerr.NewValidation().AddMessage("amount", "min", "amount must be positive", -1)
+	s.err = goerr.WithCode[int](v.Err(), 4901)
+}
+
+func (s *TestEncoderSuite) TearDownTest() {
+	SetOptions()
+}
+
+func (s *TestEncoderSuite) write(accept string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	r.Header.Set("Accept", accept)
+	r.Header.Set("X-Request-Id", "req-1")
+	w := httptest.NewRecorder()
+	WriteError(w, r, s.err)
+	return w
+}
+
+func (s *TestEncoderSuite) TestXML() {
+	w := s.write("application/xml")
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+	s.Equal(xml.Header+`<error><httpCode>400</httpCode><businessCode>4901</businessCode><msg>invalid order</msg>`+
+		`<fields><field><field>amount</field><constraint>min</constraint><message>amount must be positive</message>`+
+		`<rejectedValue>-1</rejectedValue></field></fields><requestId>req-1</requestId></error>`, w.Body.String())
+
+	var resp Response
+	s.NoError(xml.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal(4901, resp.BusinessCode)
+	s.Equal("amount", resp.Fields[0].Field)
+
+	s.Equal("text/xml; charset=utf-8", s.write("text/xml").Header().Get("Content-Type"))
+
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	r.Header.Set("Accept", "application/xml")
+	w = httptest.NewRecorder()
+	WriteError(w, r, goerr.WithCode[int](nil, 4901))
+	s.Equal(xml.Header+`<error><httpCode>400</httpCode><businessCode>4901</businessCode><msg>invalid order</msg></error>`,
+		w.Body.String())
+
+	s.Equal(4901, s.remote("application/xml").BusinessCode)
+	s.Equal("invalid order", s.remote("application/xml").Msg)
+}
+
+func (s *TestEncoderSuite) TestEnvelope() {
+	SetOptions(WithEnvelope(func(_ *http.Request, resp *Response) any {
+		return map[string]any{"code": resp.BusinessCode}
+	}))
+	s.Equal(xml.Header+`<error><code>4901</code></error>`, s.write("application/xml").Body.String())
+	s.JSONEq(`{"code":4901}`, s.write("application/json").Body.String())
+
+	SetOptions(WithTemplate(goerr.Envelope{
+		"code":  goerr.PropBusinessCode,
+		"data":  nil,
+		"error": goerr.Envelope{"reason": goerr.PropMessage, "fields": goerr.PropFields},
+	}))
+	s.JSONEq(`{"code":4901,"data":null,"error":{"reason":"invalid order","fields":[`+
+		`{"field":"amount","constraint":"min","message":"amount must be positive","rejectedValue":-1}]}}`,
+		s.write("application/json").Body.String())
+	s.Equal(xml.Header+`<error><code>4901</code><data></data><error><fields><field><field>amount</field>`+
+		`<constraint>min</constraint><message>amount must be positive</message><rejectedValue>-1</rejectedValue>`+
+		`</field></fields><reason>invalid order</reason></error></error>`, s.write("application/xml").Body.String())
+
+	for _, accept := range []string{"application/json", "application/xml"} {
+		remote := s.remote(accept, WithDecodeTemplate(goerr.Envelope{
+			"code":  goerr.PropBusinessCode,
+			"error": goerr.Envelope{"reason": goerr.PropMessage, "fields": goerr.PropFields},
+		}))
+		s.Equal(4901, remote.BusinessCode)
+		s.Equal("invalid order", remote.Msg)
+		s.Require().Len(remote.Fields, 1)
+		s.Equal("amount must be positive", remote.Fields[0].Message)
+	}
+}
+
+// remote 请求返回s.err的服务，并解析其错误响应
+func (s *TestEncoderSuite) remote(accept string, options ...DecodeOption) *RemoteError {
+	server := httptest.NewServer(Handler(func(http.ResponseWriter, *http.Request) error { return s.err }))
+	defer server.Close()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Accept", accept)
+	r, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer r.Body.Close()
+	var re *RemoteError
+	s.Require().True(goerr.As(DecodeResponse(r, options...), &re))
+	return re
+}
+
+func (s *TestEncoderSuite) TestRegister() {
+	RegisterEncoder("application/x-test", func(w io.Writer, body any) error {
+		resp, ok := body.(*Response)
+		if !ok {
+			return fmt.Errorf("unsupported body %T", body)
+		}
+		_, err := fmt.Fprintf(w, "%d|%s", resp.BusinessCode, resp.Msg)
+		return err
+	})
+	w := s.write("application/x-test")
+	s.Equal("application/x-test", w.Header().Get("Content-Type"))
+	s.Equal("4901|invalid order", w.Body.String())
+
+	s.Equal("application/json; charset=utf-8", s.write("").Header().Get("Content-Type"))
+}
+
+func TestEncoder(t *testing.T) {
+	suite.Run(t, &TestEncoderSuite{})
+}
@@ -0,0 +1,98 @@
+package httperr
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/yushengji/goerr"
+)
+
+// Option 错误响应配置项
+type Option func(*config)
+
+// Envelope 将错误响应内容包装为最终序列化的响应体
+type Envelope func(r *http.Request, resp *Response) any
+
+type config struct {
+	envelope        Envelope
+	requestIDHeader string
+	problem         bool
+	problemType     string
+	fallbackStatus  int
+}
+
+var cfg atomic.Pointer[config]
+
+func init() {
+	SetOptions()
+}
+
+// SetOptions 设置错误响应配置，作用于 WriteError 与 Handler
+// 每次调用都会先恢复默认配置，再依次应用传入的配置项
+func SetOptions(options ...Option) {
+	c := &config{
+		envelope:        plainEnvelope,
+		requestIDHeader: "X-Request-Id",
+		problemType:     "urn:goerr:code:",
+		fallbackStatus:  http.StatusInternalServerError,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	cfg.Store(c)
+}
+
+// WithEnvelope 设置响应体的包装方式，默认使用 goerr.SetEnvelope 设置的模板，未设置时直接序列化 Response
+func WithEnvelope(envelope Envelope) Option {
+	return func(c *config) {
+		c.envelope = envelope
+	}
+}
+
+// WithTemplate 使用声明式的模板包装响应体
+func WithTemplate(template goerr.Envelope) Option {
+	return func(c *config) {
+		c.envelope = func(_ *http.Request, resp *Response) any {
+			return template.Render(resp.values())
+		}
+	}
+}
+
+// WithRequestIDHeader 设置请求ID所在的请求头，默认为 X-Request-Id
+// 请求中携带的请求ID会原样写入响应头与响应体
+func WithRequestIDHeader(header string) Option {
+	return func(c *config) {
+		c.requestIDHeader = header
+	}
+}
+
+// WithProblemDetails 默认使用 RFC 9457 定义的 application/problem+json 格式输出错误响应，
+// 未设置时仅在 Accept 要求该格式时使用
+func WithProblemDetails() Option {
+	return func(c *config) {
+		c.problem = true
+	}
+}
+
+// WithProblemType 设置问题详情中 type 的前缀，type 由前缀与业务码拼接而成，
+// 默认为 urn:goerr:code:
+func WithProblemType(prefix string) Option {
+	return func(c *config) {
+		c.problemType = prefix
+	}
+}
+
+// WithFallbackStatus 设置错误的HTTP码小于400时使用的响应码，默认为500
+// 未携带错误码的错误解析得到的HTTP码为默认错误码的200，错误响应不应使用2xx、3xx响应码
+func WithFallbackStatus(status int) Option {
+	return func(c *config) {
+		c.fallbackStatus = status
+	}
+}
+
+func plainEnvelope(_ *http.Request, resp *Response) any {
+	if template := goerr.GetEnvelope(); template != nil {
+		return template.Render(resp.values())
+	}
+	return resp
+}
@@ -0,0 +1,84 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yushengji/goerr"
+)
+
+// Problem RFC 9457 定义的问题详情，业务码、字段校验失败信息与请求ID作为扩展成员
+type Problem struct {
+	// Type 问题类型，由 WithProblemType 设置的前缀与业务码拼接而成
+	Type string `json:"type"`
+	// Title 错误码注册时的信息，未注册信息时为HTTP码对应的状态文本
+	Title string `json:"title"`
+	// Status 响应码
+	Status int `json:"status"`
+	// Detail 可以对外暴露的错误信息
+	Detail string `json:"detail,omitempty"`
+	// Instance 发生错误的请求路径
+	Instance string `json:"instance,omitempty"`
+	// BusinessCode 业务码
+	BusinessCode int `json:"businessCode"`
+	// Fields 字段校验失败信息
+	Fields []goerr.FieldViolation `json:"fields,omitempty"`
+	// RequestID 请求中携带的请求ID
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// NewProblem 将错误解析为问题详情
+func NewProblem(r *http.Request, err error) *Problem {
+	c := cfg.Load()
+	resp := c.response(r, err)
+	return c.newProblem(r, resp, resp.HttpCode)
+}
+
+func (c *config) newProblem(r *http.Request, resp *Response, status int) *Problem {
+	p := &Problem{
+		Type:         c.problemType + strconv.Itoa(resp.BusinessCode),
+		Title:        http.StatusText(status),
+		Status:       status,
+		Detail:       resp.Msg,
+		BusinessCode: resp.BusinessCode,
+		Fields:       resp.Fields,
+		RequestID:    resp.RequestID,
+	}
+	if code, ok := goerr.LookupCode(resp.BusinessCode); ok && code.Message != "" {
+		p.Title = code.Message
+	}
+	if r != nil {
+		p.Instance = r.URL.Path
+	}
+	return p
+}
+
+// ParseProblem 解析问题详情，缺少 businessCode 扩展成员时尝试以 type 末尾的数字作为业务码
+// 问题详情通常来自其他服务，其 type 前缀不一定与本服务相同
+func ParseProblem(data []byte) (*Problem, error) {
+	var p Problem
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, goerr.Wrap(err, "parse problem details")
+	}
+	if p.BusinessCode == 0 {
+		i := strings.LastIndexFunc(p.Type, func(r rune) bool { return r < '0' || r > '9' })
+		p.BusinessCode, _ = strconv.Atoi(p.Type[i+1:])
+	}
+	return &p, nil
+}
+
+// Err 将问题详情还原为错误码错误，可以使用 goerr.IsCode 与 goerr.ParseCode 解析
+// 错误信息优先使用 Detail，为空时使用 Title
+func (p *Problem) Err() error {
+	msg := p.Detail
+	if msg == "" {
+		msg = p.Title
+	}
+	return goerr.FromCode(nil, goerr.ErrCode{
+		HttpCode:     p.Status,
+		BusinessCode: p.BusinessCode,
+		Message:      msg,
+	}, goerr.WithFields(p.Fields...))
+}
@@ -0,0 +1,87 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yushengji/goerr"
+)
+
+type TestProblemSuite struct {
+	suite.Suite
+	err error
+}
+
+func (s *TestProblemSuite) SetupTest() {
+	goerr.NewNotFound(4201, "order not found")
+	v := goerr.NewValidation().AddMessage("id", "exists", "order 7 does not exist", 7)
+	s.err = goerr.WithCode[int](v.Err(), 4201, goerr.WithMessage("order 7 not found"))
+}
+
+func (s *TestProblemSuite) TearDownTest() {
+	SetOptions()
+}
+
+func (s *TestProblemSuite) TestWrite() {
+	r := httptest.NewRequest(http.MethodGet, "/orders/7?token=secret", nil)
+	r.Header.Set("Accept", "application/problem+json")
+	r.Header.Set("X-Request-Id", "req-1")
+	w := httptest.NewRecorder()
+	WriteError(w, r, s.err)
+
+	s.Equal(http.StatusNotFound, w.Code)
+	s.Equal("application/problem+json", w.Header().Get("Content-Type"))
+	s.JSONEq(`{
+		"type": "urn:goerr:code:4201",
+		"title": "order not found",
+		"status": 404,
+		"detail": "order 7 not found",
+		"instance": "/orders/7",
+		"businessCode": 4201,
+		"fields": [{"field": "id", "constraint": "exists", "message": "order 7 does not exist", "rejectedValue": 7}],
+		"requestId": "req-1"
+	}`, w.Body.String())
+
+	SetOptions(WithProblemDetails(), WithProblemType("https://errors.example.com/"))
+	w = httptest.NewRecorder()
+	WriteError(w, httptest.NewRequest(http.MethodGet, "/orders/7", nil), s.err)
+	s.Equal("application/problem+json", w.Header().Get("Content-Type"))
+	s.Contains(w.Body.String(), `"type":"https://errors.example.com/4201"`)
+}
+
+func (s *TestProblemSuite) TestParse() {
+	data, err := json.Marshal(NewProblem(nil, s.err))
+	s.NoError(err)
+	p, err := ParseProblem(data)
+	s.NoError(err)
+
+	remote := p.Err()
+	s.True(goerr.IsCode(remote, 4201))
+	code := goerr.ParseCode(remote)
+	s.Equal(http.StatusNotFound, code.HttpCode)
+	s.Equal("order 7 not found", code.Msg)
+	s.Len(code.Fields, 1)
+
+	p, err = ParseProblem([]byte(`{"type":"urn:goerr:code:4201","title":"order not found","status":404}`))
+	s.NoError(err)
+	s.Equal(4201, p.BusinessCode)
+	s.Equal("order not found", p.Err().Error())
+
+	SetOptions(WithProblemType("https://errors.example.com/"))
+	p, err = ParseProblem([]byte(`{"type":"https://errors.other.com/codes/4201","status":404}`))
+	s.NoError(err)
+	s.Equal(4201, p.BusinessCode)
+	p, err = ParseProblem([]byte(`{"type":"about:blank","status":404}`))
+	s.NoError(err)
+	s.Zero(p.BusinessCode)
+
+	_, err = ParseProblem([]byte(`not json`))
+	s.Error(err)
+}
+
+func TestProblem(t *testing.T) {
+	suite.Run(t, &TestProblemSuite{})
+}
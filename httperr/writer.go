@@ -0,0 +1,226 @@
+// Package httperr 将 goerr 错误写为HTTP错误响应
+//
+// 响应码、业务码取自 goerr.ParseCode 的结果，错误信息按照 goerr.SetMessagePolicy 设置的策略选取，
+// 响应体格式根据请求的 Accept 协商，内置JSON、XML、RFC 9457 问题详情与纯文本，
+// 其他格式可以通过 RegisterEncoder 注册
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yushengji/goerr"
+)
+
+const (
+	mimeJSON    = "application/json"
+	mimeProblem = "application/problem+json"
+	mimeText    = "text/plain"
+)
+
+// Response 错误响应的内容
+// 各编码格式使用相同的字段名
+type Response struct {
+	XMLName xml.Name `json:"-" xml:"error"`
+	// HttpCode 响应码
+	HttpCode int `json:"httpCode" xml:"httpCode"`
+	// BusinessCode 业务码
+	BusinessCode int `json:"businessCode" xml:"businessCode"`
+	// Msg 可以对外暴露的错误信息
+	Msg string `json:"msg" xml:"msg"`
+	// Fields 字段校验失败信息
+	Fields []goerr.FieldViolation `json:"fields,omitempty" xml:"fields>field,omitempty"`
+	// RequestID 请求中携带的请求ID
+	RequestID string `json:"requestId,omitempty" xml:"requestId,omitempty"`
+}
+
+// NewResponse 将错误解析为错误响应的内容
+func NewResponse(r *http.Request, err error) *Response {
+	return cfg.Load().response(r, err)
+}
+
+func (c *config) response(r *http.Request, err error) *Response {
+	code := goerr.ParseCode(err)
+	resp := &Response{
+		HttpCode:     code.HttpCode,
+		BusinessCode: code.BusinessCode,
+		Msg:          goerr.PublicMessage(err),
+		Fields:       code.Fields,
+	}
+	if resp.HttpCode < 400 {
+		resp.HttpCode = c.fallbackStatus
+	}
+	if r != nil {
+		resp.RequestID = r.Header.Get(c.requestIDHeader)
+	}
+	return resp
+}
+
+// values 渲染响应体模板时使用的属性值
+func (resp *Response) values() map[goerr.Property]any {
+	values := map[goerr.Property]any{
+		goerr.PropHttpCode:     resp.HttpCode,
+		goerr.PropBusinessCode: resp.BusinessCode,
+		goerr.PropMessage:      resp.Msg,
+	}
+	if len(resp.Fields) > 0 {
+		values[goerr.PropFields] = resp.Fields
+	}
+	if resp.RequestID != "" {
+		values[goerr.PropRequestID] = resp.RequestID
+	}
+	return values
+}
+
+// WriteError 将错误写为HTTP错误响应，err为nil时不做任何操作
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	c := cfg.Load()
+	resp := c.response(r, err)
+
+	h := w.Header()
+	h.Set("X-Content-Type-Options", "nosniff")
+	if resp.RequestID != "" {
+		h.Set(c.requestIDHeader, resp.RequestID)
+	}
+	setRetryHeaders(h, err)
+	status := resp.HttpCode
+	if status < 100 || status > 999 {
+		status = http.StatusInternalServerError
+	}
+
+	var accept string
+	if r != nil {
+		accept = r.Header.Get("Accept")
+	}
+	switch mediaType := negotiate(accept, c.offers()); mediaType {
+	case mimeText:
+		h.Set("Content-Type", mimeText+"; charset=utf-8")
+		w.WriteHeader(status)
+		writeText(w, resp)
+	case mimeProblem:
+		body, _ := json.Marshal(c.newProblem(r, resp, status))
+		h.Set("Content-Type", mimeProblem)
+		w.WriteHeader(status)
+		w.Write(body)
+	default:
+		enc := lookupEncoder(mediaType)
+		var body bytes.Buffer
+		if enc.encode(&body, c.envelope(r, resp)) != nil {
+			// 自定义的响应体无法编码时退回默认的响应体
+			body.Reset()
+			enc.encode(&body, resp)
+		}
+		h.Set("Content-Type", enc.contentType)
+		w.WriteHeader(status)
+		w.Write(body.Bytes())
+	}
+}
+
+// Handler 将返回错误的处理函数转换为 http.Handler，返回的错误通过 WriteError 写为错误响应
+func Handler(f func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := f(w, r); err != nil {
+			WriteError(w, r, err)
+		}
+	})
+}
+
+// Recover 捕获处理请求时发生的panic，通过 goerr.Recover 转换为错误码错误后由 WriteError 写为错误响应
+// 与 net/http 的约定相同，http.ErrAbortHandler 会被继续抛出
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		defer func() {
+			if errors.Is(err, http.ErrAbortHandler) {
+				panic(http.ErrAbortHandler)
+			}
+			WriteError(w, r, err)
+		}()
+		defer goerr.Recover(&err)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setRetryHeaders 根据错误携带的重试时间与限流信息设置 Retry-After 与 RateLimit-* 响应头
+func setRetryHeaders(h http.Header, err error) {
+	if d, ok := goerr.RetryAfter(err); ok {
+		h.Set("Retry-After", seconds(d))
+	}
+	if limit, ok := goerr.RateLimitOf(err); ok {
+		h.Set("RateLimit-Limit", strconv.Itoa(limit.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(limit.Remaining))
+		h.Set("RateLimit-Reset", seconds(limit.Reset))
+	}
+}
+
+// seconds 将时间向上取整为秒数
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
+}
+
+func writeText(w io.Writer, resp *Response) {
+	io.WriteString(w, resp.Msg+"\n")
+	for _, f := range resp.Fields {
+		io.WriteString(w, f.Field+": "+f.Message+"\n")
+	}
+}
+
+// negotiate 根据 Accept 选取质量值最高的格式，质量值相同时选取排在前面的格式，
+// 没有可接受的格式时使用第一个格式
+func negotiate(accept string, offers []string) string {
+	best, bestQ := offers[0], 0.0
+	if strings.TrimSpace(accept) == "" {
+		return best
+	}
+	for _, offer := range offers {
+		if q := quality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// quality 获取offer在 Accept 中的质量值，以最具体的匹配项为准
+func quality(accept, offer string) float64 {
+	q, spec := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, _ := strings.Cut(part, ";")
+		s := specificity(strings.ToLower(strings.TrimSpace(mediaRange)), offer)
+		if s <= spec {
+			continue
+		}
+		spec, q = s, 1
+		for _, param := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(k) != "q" {
+				continue
+			}
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return q
+}
+
+func specificity(mediaRange, offer string) int {
+	switch {
+	case mediaRange == offer:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	}
+	return -1
+}
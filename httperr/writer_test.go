@@ -0,0 +1,219 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yushengji/goerr"
+)
+
+type TestWriterSuite struct {
+	suite.Suite
+	badErr, internalErr error
+}
+
+func (s *TestWriterSuite) SetupTest() {
+	goerr.NewBadRequest(4101, "bad order")
+	goerr.NewInternalError(4102, "order service error")
+	v := goerr.NewValidation().AddMessage("amount", "min", "amount must be positive", nil)
+	s.badErr = goerr.WithCode[int](v.Err(), 4101, goerr.WithMessage("invalid order"))
+	s.internalErr = goerr.WithCode[int](errors.New("connection refused"), 4102)
+}
+
+func (s *TestWriterSuite) TearDownTest() {
+	SetOptions()
+	goerr.SetMessagePolicy(goerr.PublicClient)
+}
+
+func (s *TestWriterSuite) write(err error, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	WriteError(w, r, err)
+	return w
+}
+
+func (s *TestWriterSuite) TestJSON() {
+	w := s.write(s.badErr, "X-Request-Id", "req-1")
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	s.Equal("req-1", w.Header().Get("X-Request-Id"))
+
+	var resp Response
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	s.Equal(4101, resp.BusinessCode)
+	s.Equal("invalid order", resp.Msg)
+	s.Equal("req-1", resp.RequestID)
+	s.Len(resp.Fields, 1)
+	s.Equal("amount", resp.Fields[0].Field)
+
+	w = s.write(s.internalErr)
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.JSONEq(`{"httpCode":500,"businessCode":4102,"msg":"order service error"}`, w.Body.String())
+	s.Empty(w.Header().Get("X-Request-Id"))
+
+	wrapped := goerr.Wrap(s.internalErr, "create order")
+	s.Contains(s.write(wrapped).Body.String(), `"msg":"order service error"`)
+	goerr.SetMessagePolicy(goerr.PublicAll)
+	s.Contains(s.write(wrapped).Body.String(), `"msg":"create order"`)
+
+	w = httptest.NewRecorder()
+	WriteError(w, nil, nil)
+	s.Equal(http.StatusOK, w.Code)
+	s.Zero(w.Body.Len())
+}
+
+func (s *TestWriterSuite) TestUncoded() {
+	err := errors.New("db down")
+	w := s.write(err)
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.JSONEq(`{"httpCode":500,"businessCode":0,"msg":"Internal Server Error"}`, w.Body.String())
+
+	w = s.write(err, "Accept", "application/xml")
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Contains(w.Body.String(), "<httpCode>500</httpCode>")
+
+	goerr.SetMessagePolicy(goerr.PublicAll)
+	SetOptions(WithFallbackStatus(http.StatusBadGateway))
+	w = s.write(err)
+	s.Equal(http.StatusBadGateway, w.Code)
+	s.JSONEq(`{"httpCode":502,"businessCode":0,"msg":"db down"}`, w.Body.String())
+}
+
+func (s *TestWriterSuite) TestText() {
+	w := s.write(s.badErr, "Accept", "text/plain, application/json;q=0.5")
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	s.Equal("invalid order\namount: amount must be positive\n", w.Body.String())
+
+	w = s.write(s.badErr, "Accept", "text/html, */*;q=0.1")
+	s.Equal("application/json; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func (s *TestWriterSuite) TestOptions() {
+	SetOptions(
+		WithRequestIDHeader("X-Trace-Id"),
+		WithEnvelope(func(_ *http.Request, resp *Response) any {
+			return map[string]any{"code": resp.BusinessCode, "msg": resp.Msg, "traceId": resp.RequestID}
+		}),
+	)
+	w := s.write(s.badErr, "X-Trace-Id", "trace-1")
+	s.Equal("trace-1", w.Header().Get("X-Trace-Id"))
+	s.JSONEq(`{"code":4101,"msg":"invalid order","traceId":"trace-1"}`, w.Body.String())
+
+	SetOptions(WithEnvelope(func(*http.Request, *Response) any { return func() {} }))
+	s.JSONEq(`{"httpCode":500,"businessCode":4102,"msg":"order service error"}`,
+		s.write(s.internalErr).Body.String())
+}
+
+func (s *TestWriterSuite) TestTemplate() {
+	mobile := goerr.Envelope{"code": goerr.PropBusinessCode, "msg": goerr.PropMessage, "data": nil, "traceId": goerr.PropRequestID}
+	SetOptions(WithTemplate(mobile))
+	w := s.write(s.internalErr, "X-Request-Id", "req-1")
+	s.JSONEq(`{"code":4102,"msg":"order service error","data":null,"traceId":"req-1"}`, w.Body.String())
+
+	SetOptions()
+	goerr.SetEnvelope(goerr.Envelope{"error": goerr.Envelope{"status": goerr.PropHttpCode, "reason": goerr.PropMessage}})
+	defer goerr.SetEnvelope(nil)
+	w = s.write(s.internalErr)
+	s.JSONEq(`{"error":{"status":500,"reason":"order service error"}}`, w.Body.String())
+
+	// 下游服务使用 mobile 模板，与本服务的模板不同
+	remote := httptest.NewServer(Handler(func(http.ResponseWriter, *http.Request) error { return s.internalErr }))
+	defer remote.Close()
+	SetOptions(WithTemplate(mobile))
+	resp, err := http.Get(remote.URL)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+	var re *RemoteError
+	s.Require().True(goerr.As(DecodeResponse(resp, WithDecodeTemplate(mobile)), &re))
+	s.Equal(4102, re.BusinessCode)
+	s.Equal("order service error", re.Msg)
+
+	client := &http.Client{Transport: &Transport{Template: mobile}}
+	_, err = client.Get(remote.URL)
+	s.Require().True(goerr.As(err, &re))
+	s.Equal(4102, re.BusinessCode)
+}
+
+func (s *TestWriterSuite) TestHandler() {
+	h := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Query().Get("id") == "" {
+			return s.badErr
+		}
+		_, err := w.Write([]byte("ok"))
+		return err
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	s.Equal(http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders?id=1", nil))
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("ok", w.Body.String())
+}
+
+func (s *TestWriterSuite) TestRecover() {
+	h := Recover(Handler(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Query().Get("id") == "" {
+			var order map[string]int
+			order["amount"] = 1
+		}
+		return s.badErr
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Contains(w.Body.String(), `"msg":"Internal Server Error"`)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders?id=1", nil))
+	s.Equal(http.StatusBadRequest, w.Code)
+
+	abort := Recover(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	s.PanicsWithValue(http.ErrAbortHandler, func() {
+		abort.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func (s *TestWriterSuite) TestRetryHeaders() {
+	goerr.NewTooManyRequests(4103, "too many orders")
+	err := goerr.WithCode[int](nil, 4103,
+		goerr.WithRetryAfter(1500*time.Millisecond), goerr.WithRateLimit(10, 0, time.Minute))
+	w := s.write(err)
+	s.Equal(http.StatusTooManyRequests, w.Code)
+	s.Equal("2", w.Header().Get("Retry-After"))
+	s.Equal("10", w.Header().Get("RateLimit-Limit"))
+	s.Equal("0", w.Header().Get("RateLimit-Remaining"))
+	s.Equal("60", w.Header().Get("RateLimit-Reset"))
+
+	w = s.write(s.badErr)
+	s.Empty(w.Header().Get("Retry-After"))
+	s.Empty(w.Header().Get("RateLimit-Limit"))
+}
+
+func (s *TestWriterSuite) TestNegotiate() {
+	offers := []string{"application/json", "text/plain"}
+	s.Equal("application/json", negotiate("", offers))
+	s.Equal("application/json", negotiate("image/png", offers))
+	s.Equal("application/json", negotiate("*/*", offers))
+	s.Equal("text/plain", negotiate("text/*", offers))
+	s.Equal("text/plain", negotiate("application/json;q=0.2, text/plain;q=0.8", offers))
+	s.Equal("application/json", negotiate("text/plain;q=0, */*", offers))
+}
+
+func TestWriter(t *testing.T) {
+	suite.Run(t, &TestWriterSuite{})
+}
@@ -0,0 +1,112 @@
+// Package buildid 读取二进制文件中记录的Go构建ID
+//
+// 仅解析ELF文件头、程序头与note段，不依赖 debug/elf，
+// 避免所有引入 goerr 的程序都链接完整的ELF解析实现
+package buildid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+const (
+	ptNote     = 4
+	noteGoType = 4
+	noteGoName = "Go\x00\x00"
+)
+
+// Read 读取二进制文件中 .note.go.buildid 段记录的Go构建ID
+// 目前仅支持ELF格式，读取失败或非ELF文件时返回空字符串
+func Read(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var ident [16]byte
+	if _, err := io.ReadFull(f, ident[:]); err != nil || string(ident[:4]) != "\x7fELF" {
+		return ""
+	}
+	var order binary.ByteOrder
+	switch ident[5] {
+	case 1:
+		order = binary.LittleEndian
+	case 2:
+		order = binary.BigEndian
+	default:
+		return ""
+	}
+
+	// 文件头中程序头表的位置与大小，32位与64位布局不同
+	var phoff uint64
+	var phentsize, phnum uint16
+	var offOff, sizeOff int
+	hdr := make([]byte, 64)
+	if _, err := f.ReadAt(hdr, 0); err != nil && err != io.EOF {
+		return ""
+	}
+	switch ident[4] {
+	case 1:
+		phoff = uint64(order.Uint32(hdr[0x1c:]))
+		phentsize, phnum = order.Uint16(hdr[0x2a:]), order.Uint16(hdr[0x2c:])
+		offOff, sizeOff = 4, 16
+	case 2:
+		phoff = order.Uint64(hdr[0x20:])
+		phentsize, phnum = order.Uint16(hdr[0x36:]), order.Uint16(hdr[0x38:])
+		offOff, sizeOff = 8, 32
+	default:
+		return ""
+	}
+
+	ph := make([]byte, phentsize)
+	for i := range uint64(phnum) {
+		if _, err := f.ReadAt(ph, int64(phoff+i*uint64(phentsize))); err != nil {
+			return ""
+		}
+		if order.Uint32(ph) != ptNote {
+			continue
+		}
+		var off, size uint64
+		if ident[4] == 1 {
+			off, size = uint64(order.Uint32(ph[offOff:])), uint64(order.Uint32(ph[sizeOff:]))
+		} else {
+			off, size = order.Uint64(ph[offOff:]), order.Uint64(ph[sizeOff:])
+		}
+		if size > 1<<16 {
+			continue
+		}
+		notes := make([]byte, size)
+		if _, err := f.ReadAt(notes, int64(off)); err != nil {
+			continue
+		}
+		if id, ok := findNote(notes, order); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// findNote 在note段中查找Go构建ID
+// note格式：namesz、descsz、type各4字节，随后为4字节对齐的名称与内容
+func findNote(notes []byte, order binary.ByteOrder) (string, bool) {
+	align := func(n uint64) uint64 { return (n + 3) &^ 3 }
+	for len(notes) >= 12 {
+		namesz := uint64(order.Uint32(notes[0:]))
+		descsz := uint64(order.Uint32(notes[4:]))
+		typ := order.Uint32(notes[8:])
+		notes = notes[12:]
+		if align(namesz)+align(descsz) > uint64(len(notes)) {
+			return "", false
+		}
+		name := notes[:align(namesz)]
+		desc := notes[align(namesz) : align(namesz)+descsz]
+		notes = notes[align(namesz)+align(descsz):]
+		if typ == noteGoType && bytes.Equal(name, []byte(noteGoName)) {
+			return string(desc), true
+		}
+	}
+	return "", false
+}
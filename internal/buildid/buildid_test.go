@@ -0,0 +1,38 @@
+package buildid
+
+import (
+	"debug/elf"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestBuildIDSuite struct {
+	suite.Suite
+}
+
+func (s *TestBuildIDSuite) TestRead() {
+	exe, _ := os.Executable()
+	f, err := elf.Open(exe)
+	if err != nil {
+		s.T().Skip("test binary is not ELF")
+	}
+	defer f.Close()
+
+	// 与 debug/elf 解析 .note.go.buildid 段的结果一致
+	note, err := f.Section(".note.go.buildid").Data()
+	s.NoError(err)
+	size := f.ByteOrder.Uint32(note[4:8])
+	s.Equal(string(note[16:16+size]), Read(exe))
+	s.NotEmpty(Read(exe))
+}
+
+func (s *TestBuildIDSuite) TestNotELF() {
+	s.Empty(Read("buildid.go"))
+	s.Empty(Read("not-exist"))
+}
+
+func TestBuildID(t *testing.T) {
+	suite.Run(t, &TestBuildIDSuite{})
+}
@@ -0,0 +1,159 @@
+package goerr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// JoinPolicy ParseCode 解析 Join 产生的多错误时选取错误码的策略
+type JoinPolicy int
+
+const (
+	// JoinFirst 使用第一个带有错误码的分支
+	JoinFirst JoinPolicy = iota
+	// JoinSevere 使用HTTP码类别最严重的分支，例如5xx优先于4xx，类别相同时使用靠前的分支
+	JoinSevere
+	// JoinAggregate 使用 SetAggregateCode 设置的聚合错误码
+	JoinAggregate
+)
+
+var (
+	joinPolicy    atomic.Int32
+	aggregateCode atomic.Int64
+)
+
+// SetJoinPolicy 设置 ParseCode 解析多错误时选取错误码的策略，默认为 JoinFirst
+func SetJoinPolicy(policy JoinPolicy) {
+	joinPolicy.Store(int32(policy))
+}
+
+// SetAggregateCode 设置多错误的聚合错误码，并使用 JoinAggregate 策略
+// 与 WithCode 相同，业务码会拼接应用码
+func SetAggregateCode[T codeType](code T) {
+	aggregateCode.Store(int64(code))
+	joinPolicy.Store(int32(JoinAggregate))
+}
+
+type multiError struct {
+	errs []error
+}
+
+func (m *multiError) Error() string {
+	msgs := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (m *multiError) Unwrap() []error { return m.errs }
+
+func (m *multiError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			printVerbose(s, m)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, m.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", m.Error())
+	}
+}
+
+// Join 将多个错误合并为一个错误，nil会被忽略，全部为nil时返回nil
+// 每个分支均会保留各自的堆栈，%+v 以缩进的树形结构输出各分支，
+// Is、As 会依次匹配各分支，UnWrap 遇到多错误时停止并返回该多错误
+func Join(errs ...error) error {
+	var branches []error
+	for _, err := range errs {
+		if err != nil {
+			branches = append(branches, wrapStack(err))
+		}
+	}
+	if len(branches) == 0 {
+		return nil
+	}
+	return &multiError{errs: branches}
+}
+
+// Append 将错误追加到err中，若err为 Join 产生的多错误，则追加为其新的分支
+func Append(err error, errs ...error) error {
+	var branches []error
+	if m, ok := err.(*multiError); ok {
+		branches = append(branches, m.errs...)
+	} else if err != nil {
+		branches = append(branches, wrapStack(err))
+	}
+	for _, e := range errs {
+		if e != nil {
+			branches = append(branches, wrapStack(e))
+		}
+	}
+	if len(branches) == 0 {
+		return nil
+	}
+	return &multiError{errs: branches}
+}
+
+// Errors 获取错误链中第一个多错误的全部分支，不存在多错误时返回nil
+func Errors(err error) []error {
+	var m *multiError
+	if As(err, &m) {
+		return m.errs
+	}
+	return nil
+}
+
+// findCode 查找错误链中的错误码错误，遇到多错误时按照策略在各分支中选取
+func findCode(err error) *withCode {
+	return findCodeGuarded(err, &chainGuard{})
+}
+
+func findCodeGuarded(err error, g *chainGuard) *withCode {
+	defer g.reset(g.mark())
+	for err != nil && g.enter(err) == chainOK {
+		switch e := err.(type) {
+		case *withCode:
+			return e
+		case *BatchError:
+			return e.code()
+		case interface{ Unwrap() []error }:
+			return joinCode(e.Unwrap(), g)
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
+
+// joinCode 按照多错误策略选取错误码
+func joinCode(errs []error, g *chainGuard) *withCode {
+	policy := JoinPolicy(joinPolicy.Load())
+	if policy == JoinAggregate {
+		code := getCode(serviceCode.Load() + int(aggregateCode.Load()))
+		return &withCode{
+			Msg:          code.Message,
+			HttpCode:     code.HttpCode,
+			BusinessCode: code.BusinessCode,
+		}
+	}
+	var ret *withCode
+	for _, err := range errs {
+		code := findCodeGuarded(err, g)
+		if code == nil {
+			continue
+		}
+		if policy == JoinFirst {
+			return code
+		}
+		if ret == nil || code.HttpCode/100 > ret.HttpCode/100 {
+			ret = code
+		}
+	}
+	return ret
+}
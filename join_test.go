@@ -0,0 +1,85 @@
+package goerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestJoinSuite struct {
+	suite.Suite
+	originErr, badRequestErr, internalErr, joinErr error
+}
+
+func (s *TestJoinSuite) SetupTest() {
+	NewBadRequest(3301, "bad request")
+	NewInternalError(3302, "internal error")
+	NewServiceUnavailable(3303, "aggregate error")
+
+	s.originErr = errors.New("origin error")
+	s.badRequestErr = WithCode[int](New("bad"), 3301)
+	s.internalErr = WithCode[int](New("internal"), 3302)
+	s.joinErr = Join(s.originErr, nil, s.badRequestErr, s.internalErr)
+}
+
+func (s *TestJoinSuite) TearDownTest() {
+	SetJoinPolicy(JoinFirst)
+}
+
+func (s *TestJoinSuite) TestJoin() {
+	s.Nil(Join())
+	s.Nil(Join(nil, nil))
+	s.Equal("origin error; bad request; internal error", s.joinErr.Error())
+	s.Len(Errors(s.joinErr), 3)
+	s.Len(Errors(Wrap(s.joinErr, "wrap error")), 3)
+	s.Nil(Errors(s.originErr))
+
+	s.True(Is(s.joinErr, s.originErr))
+	s.True(IsCode(s.joinErr, 3301))
+	s.True(IsCode(Wrap(s.joinErr, "wrap error"), 3302))
+	s.True(IsCode(errors.Join(s.originErr, s.internalErr), 3302))
+	s.False(IsCode(s.joinErr, 3303))
+	s.Equal(s.joinErr, UnWrap(s.joinErr))
+	s.Equal(s.joinErr, UnWrap(Wrap(s.joinErr, "wrap error")))
+}
+
+func (s *TestJoinSuite) TestAppend() {
+	s.Nil(Append(nil))
+	err := Append(s.joinErr, New("appended"))
+	s.Len(Errors(err), 4)
+	s.Len(Errors(Append(s.originErr, s.badRequestErr)), 2)
+	s.Len(Errors(s.joinErr), 3)
+}
+
+func (s *TestJoinSuite) TestFormat() {
+	out := fmt.Sprintf("%+v", s.joinErr)
+	s.True(strings.HasPrefix(out, "3 errors occurred:\n  - origin error\n    github.com/yushengji/goerr.Join\n    \t"))
+	s.Contains(out, fmt.Sprintf("\n  - bad request [biz=%d http=400]\n    bad\n", serviceCode.Load()+3301))
+	s.Contains(out, "\n    bad\n    github.com/yushengji/goerr.(*TestJoinSuite).SetupTest\n")
+	s.Equal(3, strings.Count(out, "\n  - "))
+	s.Equal("origin error; bad request; internal error", fmt.Sprintf("%s", s.joinErr))
+}
+
+func (s *TestJoinSuite) TestParseCode() {
+	s.Equal(serviceCode.Load()+3301, ParseCode(s.joinErr).BusinessCode)
+	s.Equal("origin error; bad request; internal error", ParseCode(s.joinErr).Msg)
+
+	SetJoinPolicy(JoinSevere)
+	code := ParseCode(Wrap(s.joinErr, "wrap error"))
+	s.Equal(serviceCode.Load()+3302, code.BusinessCode)
+	s.Equal(http.StatusInternalServerError, code.HttpCode)
+	s.Equal("wrap error", code.Msg)
+
+	SetAggregateCode(3303)
+	code = ParseCode(s.joinErr)
+	s.Equal(serviceCode.Load()+3303, code.BusinessCode)
+	s.Equal(http.StatusServiceUnavailable, code.HttpCode)
+}
+
+func TestJoin(t *testing.T) {
+	suite.Run(t, &TestJoinSuite{})
+}
@@ -0,0 +1,339 @@
+package goerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// jsonVersion 错误链JSON文档的版本
+const jsonVersion = 1
+
+// MarshalOption 错误链序列化配置项
+type MarshalOption func(*marshalConfig)
+
+type marshalConfig struct {
+	stacks bool
+}
+
+// WithStacks 序列化时包含符号化后的堆栈与协程信息，栈帧按照堆栈输出配置过滤
+func WithStacks() MarshalOption {
+	return func(c *marshalConfig) {
+		c.stacks = true
+	}
+}
+
+// errorDoc 错误链JSON文档
+type errorDoc struct {
+	Version int       `json:"version"`
+	Error   *jsonNode `json:"error"`
+}
+
+// jsonNode 错误链中的单层错误
+type jsonNode struct {
+	Kind         string           `json:"kind"`
+	Message      string           `json:"message,omitempty"`
+	Wrapped      bool             `json:"wrapped,omitempty"`
+	HttpCode     int              `json:"httpCode,omitempty"`
+	BusinessCode int              `json:"businessCode,omitempty"`
+	Fields       []FieldViolation `json:"fields,omitempty"`
+	RetryAfter   string           `json:"retryAfter,omitempty"`
+	RateLimit    *RateLimit       `json:"rateLimit,omitempty"`
+	Total        int              `json:"total,omitempty"`
+	Items        []jsonItem       `json:"items,omitempty"`
+	Branches     []*jsonNode      `json:"branches,omitempty"`
+	Stack        []decodedFrame   `json:"stack,omitempty"`
+	More         int              `json:"more,omitempty"`
+	Goroutine    *Goroutine       `json:"goroutine,omitempty"`
+	Cause        *jsonNode        `json:"cause,omitempty"`
+}
+
+type jsonItem struct {
+	Index *int      `json:"index,omitempty"`
+	Key   string    `json:"key,omitempty"`
+	Error *jsonNode `json:"error"`
+}
+
+// decodedFrame 符号化后的栈帧
+type decodedFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// decodedStack 反序列化得到的堆栈，本地没有对应的程序计数器，只能输出符号化后的栈帧
+type decodedStack struct {
+	frames []decodedFrame
+	// more 与内层堆栈共有而省略的栈帧数
+	more int
+}
+
+// decodedError 反序列化得到的非goerr产生的错误，只保留其错误信息
+type decodedError struct {
+	msg     string
+	cause   error
+	decoded *decodedStack
+}
+
+func (d *decodedError) Error() string { return d.msg }
+func (d *decodedError) Unwrap() error { return d.cause }
+
+// decodedJoin 反序列化得到的非goerr产生的多错误，例如 errors.Join 的结果，只保留其错误信息与各分支
+type decodedJoin struct {
+	msg     string
+	errs    []error
+	decoded *decodedStack
+}
+
+func (d *decodedJoin) Error() string   { return d.msg }
+func (d *decodedJoin) Unwrap() []error { return d.errs }
+
+// MarshalJSON 将完整的错误链序列化为带版本的JSON文档，
+// 包含每一层的错误信息、错误码、字段校验失败信息以及多错误的全部分支，
+// 使用 WithStacks 时还包含符号化后的堆栈，可以使用 UnmarshalJSON 还原
+func MarshalJSON(err error, options ...MarshalOption) ([]byte, error) {
+	cfg := &marshalConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+	return json.Marshal(errorDoc{
+		Version: jsonVersion,
+		Error:   cfg.encode(err, &chainGuard{}),
+	})
+}
+
+func (c *marshalConfig) encode(err error, g *chainGuard) *jsonNode {
+	if err == nil {
+		return nil
+	}
+	mark := g.mark()
+	if g.enter(err) != chainOK {
+		return nil
+	}
+	defer g.reset(mark)
+
+	var node *jsonNode
+	var cause error
+	switch e := err.(type) {
+	case *fundamental:
+		node = &jsonNode{Kind: "message", Message: e.msg}
+		c.stack(node, *e.stack, e.decoded, e.goroutine)
+	case *withMessage:
+		node = &jsonNode{Kind: "message", Message: e.msg, Wrapped: e.wrapped}
+		cause = e.cause
+	case *withCode:
+		node = &jsonNode{
+			Kind:         "code",
+			Message:      e.Msg,
+			HttpCode:     e.HttpCode,
+			BusinessCode: e.BusinessCode,
+			Fields:       e.Fields,
+			RateLimit:    e.rateLimit,
+		}
+		if e.retryAfter > 0 {
+			node.RetryAfter = e.retryAfter.String()
+		}
+		cause = e.cause
+	case *withStack:
+		node = &jsonNode{Kind: "stack"}
+		pcs := *e.stack
+		if cfg := loadStackConfig(); !cfg.fullStacks {
+			// 与 %+v 输出相同，省略与内层堆栈共有的栈帧
+			shared := sharedCount(pcs, stackOf(e.error))
+			node.More = cfg.kept(pcs[len(pcs)-shared:])
+			pcs = pcs[:len(pcs)-shared]
+		}
+		c.stack(node, pcs, e.decoded, e.goroutine)
+		cause = e.error
+	case *ValidationError:
+		node = &jsonNode{Kind: "validation", Fields: e.violations}
+	case *multiError:
+		node = &jsonNode{Kind: "join"}
+		for _, branch := range e.errs {
+			node.Branches = append(node.Branches, c.encode(branch, g))
+		}
+	case *BatchError:
+		node = &jsonNode{Kind: "batch", Total: e.total}
+		for _, item := range e.Failed() {
+			ji := jsonItem{Key: item.Key, Error: c.encode(item.Err, g)}
+			if item.Index >= 0 {
+				ji.Index = &item.Index
+			}
+			node.Items = append(node.Items, ji)
+		}
+	case *decodedError:
+		node = &jsonNode{Kind: "foreign", Message: e.msg}
+		c.stack(node, nil, e.decoded, nil)
+		cause = e.cause
+	case *decodedJoin:
+		node = &jsonNode{Kind: "foreign", Message: e.msg}
+		c.stack(node, nil, e.decoded, nil)
+		for _, branch := range e.errs {
+			node.Branches = append(node.Branches, c.encode(branch, g))
+		}
+	default:
+		node = &jsonNode{Kind: "foreign", Message: err.Error()}
+		pcs, _ := tracePCs(err)
+		c.stack(node, pcs, nil, nil)
+		// errors.Join、包含多个 %w 的 fmt.Errorf 等产生的多错误记录其全部分支
+		if m, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, branch := range m.Unwrap() {
+				node.Branches = append(node.Branches, c.encode(branch, g))
+			}
+		} else {
+			cause = errors.Unwrap(err)
+		}
+	}
+	node.Cause = c.encode(cause, g)
+	return node
+}
+
+// stack 在开启 WithStacks 时记录符号化后的栈帧与协程信息
+func (c *marshalConfig) stack(node *jsonNode, pcs []uintptr, decoded *decodedStack, goroutine *Goroutine) {
+	if !c.stacks {
+		node.More = 0
+		return
+	}
+	node.Goroutine = goroutine
+	if decoded != nil {
+		node.Stack, node.More = decoded.frames, decoded.more
+	}
+	cfg := loadStackConfig()
+	for _, pc := range pcs {
+		f := frame(pc)
+		if cfg.keep(f.name(), f.file()) {
+			node.Stack = append(node.Stack, decodedFrame{Function: f.name(), File: f.path(), Line: f.line()})
+		}
+	}
+}
+
+// UnmarshalJSON 将 MarshalJSON 产生的文档还原为错误decoded，文档无效时返回err，
+// 还原的错误的 Error()、IsCode、ParseCode 与 %+v 输出与原错误一致，
+// 非goerr产生的错误只能还原其错误信息与多错误的各分支，文档为空错误时decoded为nil
+func UnmarshalJSON(data []byte) (decoded error, err error) {
+	var doc errorDoc
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return nil, Wrap(err, "unmarshal error document")
+	}
+	if doc.Version != jsonVersion {
+		return nil, New("unsupported error document version %d", doc.Version)
+	}
+	return decode(doc.Error)
+}
+
+// decode 还原单层错误及其原因错误
+func decode(node *jsonNode) (decoded error, err error) {
+	if node == nil {
+		return nil, nil
+	}
+	cause, err := decode(node.Cause)
+	if err != nil {
+		return nil, err
+	}
+	switch node.Kind {
+	case "message":
+		if cause == nil {
+			return &fundamental{
+				msg:       node.Message,
+				stack:     &stack{},
+				goroutine: node.Goroutine,
+				decoded:   node.decodedStack(),
+			}, nil
+		}
+		return &withMessage{cause: cause, msg: node.Message, wrapped: node.Wrapped}, nil
+	case "code":
+		ret := &withCode{
+			cause:        cause,
+			Msg:          node.Message,
+			HttpCode:     node.HttpCode,
+			BusinessCode: node.BusinessCode,
+			Fields:       node.Fields,
+			rateLimit:    node.RateLimit,
+		}
+		if node.RetryAfter != "" {
+			if ret.retryAfter, err = time.ParseDuration(node.RetryAfter); err != nil {
+				return nil, Wrap(err, "decode retryAfter")
+			}
+		}
+		return ret, nil
+	case "stack":
+		return &withStack{
+			error:     cause,
+			stack:     &stack{},
+			goroutine: node.Goroutine,
+			decoded:   node.decodedStack(),
+		}, nil
+	case "validation":
+		return &ValidationError{violations: node.Fields}, nil
+	case "join":
+		ret := &multiError{}
+		for _, branch := range node.Branches {
+			b, err := decode(branch)
+			if err != nil {
+				return nil, err
+			}
+			if b != nil {
+				ret.errs = append(ret.errs, b)
+			}
+		}
+		return ret, nil
+	case "batch":
+		ret := NewBatch(node.Total)
+		for _, item := range node.Items {
+			b, err := decode(item.Error)
+			if err != nil {
+				return nil, err
+			}
+			if item.Index != nil {
+				ret.add(BatchItem{Index: *item.Index, Err: b})
+			} else {
+				ret.add(BatchItem{Index: -1, Key: item.Key, Err: b})
+			}
+		}
+		return ret, nil
+	case "foreign":
+		if len(node.Branches) > 0 {
+			ret := &decodedJoin{msg: node.Message, decoded: node.decodedStack()}
+			for _, branch := range node.Branches {
+				b, err := decode(branch)
+				if err != nil {
+					return nil, err
+				}
+				if b != nil {
+					ret.errs = append(ret.errs, b)
+				}
+			}
+			return ret, nil
+		}
+		return &decodedError{msg: node.Message, cause: cause, decoded: node.decodedStack()}, nil
+	}
+	return nil, New("unknown error kind %s", strconv.Quote(node.Kind))
+}
+
+func (n *jsonNode) decodedStack() *decodedStack {
+	if len(n.Stack) == 0 && n.More == 0 {
+		return nil
+	}
+	return &decodedStack{frames: n.Stack, more: n.More}
+}
+
+// format 输出反序列化得到的栈帧，格式与 %+v 输出的栈帧相同
+func (d *decodedStack) format(w io.Writer) {
+	if d == nil {
+		return
+	}
+	maskLine := loadStackConfig().maskLine
+	for _, f := range d.frames {
+		line := strconv.Itoa(f.Line)
+		if maskLine {
+			line = "?"
+		}
+		fmt.Fprintf(w, "\n%s\n\t%s:%s", f.Function, f.File, line)
+	}
+	if d.more > 0 {
+		fmt.Fprintf(w, "\n\t... %d more", d.more)
+	}
+}
@@ -0,0 +1,123 @@
+package goerr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestJSONSuite struct {
+	suite.Suite
+	err error
+}
+
+func (s *TestJSONSuite) SetupTest() {
+	NewTooManyRequests(4801, "too many orders")
+	NewBadRequest(4802, "invalid order")
+	v := NewValidation().AddMessage("amount", "min", "amount must be positive", -1)
+	batch := NewBatch(3)
+	batch.Add(0, WithCode[int](New("sku 1 locked"), 4802))
+	batch.AddKey("sku-2", v.Err())
+	joined := Join(New("first"), fmt.Errorf("read body: %w", io.EOF), batch)
+	codeErr := WithCode[int](joined, 4801,
+		WithRetryAfter(time.Second), WithRateLimit(10, 0, time.Minute),
+		WithFields(FieldViolation{Field: "id", Constraint: "exists", Message: "id not exists"}))
+	s.err = Wrap(WithStack(codeErr), "create order")
+}
+
+func (s *TestJSONSuite) TearDownTest() {
+	SetStackOptions()
+	SetMessageMode(MessageOuter)
+}
+
+func (s *TestJSONSuite) roundTrip(err error, options ...MarshalOption) error {
+	data, e := MarshalJSON(err, options...)
+	s.Require().NoError(e)
+	decoded, e := UnmarshalJSON(data)
+	s.Require().NoError(e)
+	return decoded
+}
+
+func (s *TestJSONSuite) TestRoundTrip() {
+	decoded := s.roundTrip(s.err)
+	s.Equal(s.err.Error(), decoded.Error())
+	s.True(IsCode(decoded, 4801))
+	code := ParseCode(decoded)
+	s.Equal(ParseCode(s.err).Msg, code.Msg)
+	s.Equal(http.StatusTooManyRequests, code.HttpCode)
+	s.Equal(serviceCode.Load()+4801, code.BusinessCode)
+	s.Len(code.Fields, 2)
+	s.Len(Errors(UnWrap(decoded)), 3)
+	d, ok := RetryAfter(decoded)
+	s.True(ok)
+	s.Equal(time.Second, d)
+	limit, _ := RateLimitOf(decoded)
+	s.Equal(10, limit.Limit)
+
+	SetMessageMode(MessageChain)
+	s.Equal(s.err.Error(), decoded.Error())
+
+	var batch *BatchError
+	s.Require().True(As(decoded, &batch))
+	s.Equal("2 of 3 items failed", batch.Error())
+	s.Equal("sku-2", batch.Failed()[0].Key)
+	s.Len(ParseCode(batch.Failed()[0].Err).Fields, 1)
+}
+
+func (s *TestJSONSuite) TestVerbose() {
+	SetStackOptions(WithNormalize(), WithLineMask())
+	decoded := s.roundTrip(s.err, WithStacks())
+	s.Equal(fmt.Sprintf("%+v", s.err), fmt.Sprintf("%+v", decoded))
+	s.Contains(fmt.Sprintf("%+v", decoded), "github.com/yushengji/goerr.(*TestJSONSuite).SetupTest")
+
+	decoded = s.roundTrip(decoded, WithStacks())
+	s.Equal(fmt.Sprintf("%+v", s.err), fmt.Sprintf("%+v", decoded))
+
+	s.NotContains(fmt.Sprintf("%+v", s.roundTrip(s.err)), "SetupTest")
+}
+
+func (s *TestJSONSuite) TestForeignJoin() {
+	origin := errors.New("origin")
+	for _, err := range []error{
+		errors.Join(WithCode[int](nil, 4802), origin),
+		fmt.Errorf("load %w: %w", origin, WithCode[int](nil, 4802)),
+	} {
+		decoded := s.roundTrip(err)
+		s.Equal(err.Error(), decoded.Error())
+		s.True(IsCode(decoded, 4802))
+		s.Equal(http.StatusBadRequest, ParseCode(decoded).HttpCode)
+		s.Len(decoded.(interface{ Unwrap() []error }).Unwrap(), 2)
+		s.Equal(decoded.Error(), s.roundTrip(decoded).Error())
+	}
+}
+
+func (s *TestJSONSuite) TestDocument() {
+	data, err := MarshalJSON(WithCode[int](errors.New("plain"), 4802))
+	s.NoError(err)
+	s.JSONEq(`{"version":1,"error":{"kind":"code","message":"invalid order","httpCode":400,"businessCode":`+
+		fmt.Sprint(serviceCode.Load()+4802)+`,"cause":{"kind":"stack","cause":{"kind":"foreign","message":"plain"}}}}`,
+		string(data))
+	s.Equal(http.StatusBadRequest, ParseCode(s.roundTrip(WithCode[int](errors.New("plain"), 4802))).HttpCode)
+
+	data, err = MarshalJSON(nil)
+	s.NoError(err)
+	decoded, err := UnmarshalJSON(data)
+	s.NoError(err)
+	s.Nil(decoded)
+
+	_, err = UnmarshalJSON([]byte(`{"version":2,"error":null}`))
+	s.Error(err)
+	_, err = UnmarshalJSON([]byte(`{"version":1,"error":{"kind":"unknown"}}`))
+	s.Error(err)
+	_, err = UnmarshalJSON([]byte(`{`))
+	s.Error(err)
+}
+
+func TestJSON(t *testing.T) {
+	suite.Run(t, &TestJSONSuite{})
+}
@@ -0,0 +1,102 @@
+// Package jsonrpcerr 在 goerr 错误码错误与 JSON-RPC 2.0 错误对象之间相互转换
+//
+// 错误信息按照 goerr.SetMessagePolicy 设置的策略选取，与HTTP、gRPC等协议保持一致
+package jsonrpcerr
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/yushengji/goerr"
+)
+
+// JSON-RPC 2.0 预定义的错误码
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error JSON-RPC 2.0 错误对象
+type Error struct {
+	// Code 错误码，业务码不为0时使用业务码，否则使用预定义的错误码
+	Code int `json:"code"`
+	// Message 可以对外暴露的错误信息
+	Message string `json:"message"`
+	// Data 错误码错误的附加信息
+	Data *Data `json:"data,omitempty"`
+}
+
+// Data 错误对象中的附加信息
+type Data struct {
+	// BusinessCode 业务码
+	BusinessCode int `json:"businessCode"`
+	// HttpCode HTTP码
+	HttpCode int `json:"httpCode"`
+	// Fields 字段校验失败信息
+	Fields []goerr.FieldViolation `json:"fields,omitempty"`
+}
+
+// NewError 将错误转换为 JSON-RPC 错误对象，err为nil时返回nil
+func NewError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	code := goerr.ParseCode(err)
+	if code.HttpCode < 400 {
+		// 未携带错误码的错误按服务端错误处理，与HTTP错误响应一致
+		code.HttpCode = http.StatusInternalServerError
+	}
+	return &Error{
+		Code:    rpcCode(code.BusinessCode, code.HttpCode),
+		Message: goerr.PublicMessage(err),
+		Data: &Data{
+			BusinessCode: code.BusinessCode,
+			HttpCode:     code.HttpCode,
+			Fields:       code.Fields,
+		},
+	}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// Err 将错误对象还原为错误码错误，可以使用 goerr.IsCode 与 goerr.ParseCode 解析
+// 缺少附加信息时，业务码使用错误对象的错误码，HTTP码由预定义的错误码推导
+func (e *Error) Err() error {
+	code := goerr.ErrCode{
+		HttpCode:     httpCode(e.Code),
+		BusinessCode: e.Code,
+		Message:      e.Message,
+	}
+	var options []goerr.Option
+	if e.Data != nil {
+		code.HttpCode, code.BusinessCode = e.Data.HttpCode, e.Data.BusinessCode
+		options = append(options, goerr.WithFields(e.Data.Fields...))
+	}
+	return goerr.FromCode(nil, code, options...)
+}
+
+// rpcCode 业务码不为0且不在预留范围内时使用业务码，否则按照HTTP码选取预定义的错误码
+func rpcCode(businessCode, httpCode int) int {
+	if businessCode != 0 && (businessCode < -32768 || businessCode > -32000) {
+		return businessCode
+	}
+	if httpCode == http.StatusBadRequest {
+		return CodeInvalidParams
+	}
+	return CodeInternalError
+}
+
+func httpCode(rpcCode int) int {
+	switch rpcCode {
+	case CodeParseError, CodeInvalidRequest, CodeInvalidParams:
+		return http.StatusBadRequest
+	case CodeMethodNotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,61 @@
+package jsonrpcerr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yushengji/goerr"
+)
+
+type TestErrorSuite struct {
+	suite.Suite
+	badErr, internalErr error
+}
+
+func (s *TestErrorSuite) SetupTest() {
+	goerr.NewBadRequest(4801, "invalid order")
+	goerr.NewInternalError(4802, "order service error")
+	v := goerr.NewValidation().AddMessage("amount", "min", "amount must be positive", -1)
+	s.badErr = goerr.WithCode[int](v.Err(), 4801)
+	s.internalErr = goerr.WithCode[int](errors.New("connection refused"), 4802, goerr.WithMessage("dial 10.0.0.1 failed"))
+}
+
+func (s *TestErrorSuite) TestNewError() {
+	data, err := json.Marshal(NewError(s.badErr))
+	s.NoError(err)
+	s.JSONEq(`{"code":4801,"message":"invalid order","data":{"businessCode":4801,"httpCode":400,
+		"fields":[{"field":"amount","constraint":"min","message":"amount must be positive","rejectedValue":-1}]}}`, string(data))
+
+	e := NewError(s.internalErr)
+	s.Equal("order service error", e.Message)
+	s.Equal("jsonrpc error 4802: order service error", e.Error())
+
+	e = NewError(errors.New("db down"))
+	s.Equal(CodeInternalError, e.Code)
+	s.Equal(http.StatusInternalServerError, e.Data.HttpCode)
+	s.Equal(http.StatusText(http.StatusInternalServerError), e.Message)
+	s.Nil(NewError(nil))
+}
+
+func (s *TestErrorSuite) TestErr() {
+	var e Error
+	data, _ := json.Marshal(NewError(s.badErr))
+	s.NoError(json.Unmarshal(data, &e))
+	err := e.Err()
+	s.True(goerr.IsCode(err, 4801))
+	code := goerr.ParseCode(err)
+	s.Equal(http.StatusBadRequest, code.HttpCode)
+	s.Equal("invalid order", code.Msg)
+	s.Len(code.Fields, 1)
+
+	err = (&Error{Code: CodeMethodNotFound, Message: "method not found"}).Err()
+	s.Equal(http.StatusNotFound, goerr.ParseCode(err).HttpCode)
+	s.Equal(CodeMethodNotFound, goerr.ParseCode(err).BusinessCode)
+}
+
+func TestError(t *testing.T) {
+	suite.Run(t, &TestErrorSuite{})
+}
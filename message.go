@@ -0,0 +1,143 @@
+package goerr
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// MessageMode 错误信息的组成方式
+type MessageMode int
+
+const (
+	// MessageOuter 仅使用最外层的错误信息，默认方式
+	// 例如 Wrap(dbErr, "load user") 的错误信息为 load user
+	MessageOuter MessageMode = iota
+	// MessageChain 由外向内拼接每一层的错误信息，与 fmt.Errorf("%w") 相同
+	// 例如 Wrap(dbErr, "load user") 的错误信息为 load user: connection refused
+	MessageChain
+	// MessageCoded 使用最外层错误码的信息，错误链中没有错误码时使用最外层的错误信息
+	MessageCoded
+)
+
+var messageMode atomic.Int32
+
+// SetMessageMode 设置错误信息的组成方式
+// 作用于 Wrap、WithCode 产生的错误的 Error() 以及 ParseCode 返回的错误信息
+func SetMessageMode(mode MessageMode) {
+	messageMode.Store(int32(mode))
+}
+
+func loadMessageMode() MessageMode {
+	return MessageMode(messageMode.Load())
+}
+
+// outerMsg 按照错误信息的组成方式获取错误信息
+func outerMsg(err error) string {
+	switch loadMessageMode() {
+	case MessageChain:
+		return chainMsg(err)
+	case MessageCoded:
+		if msg, ok := codedMsg(err); ok {
+			return msg
+		}
+	}
+	return firstMsg(err)
+}
+
+// firstMsg 获取最外层的错误信息
+// 仅携带堆栈的层会被跳过，非goerr产生的错误与多错误使用其完整的错误信息
+func firstMsg(err error) string {
+	var msg string
+	Walk(err, func(layer Layer) bool {
+		switch layer.Kind {
+		case LayerStack:
+			return true
+		case LayerMessage, LayerCode:
+			msg = layer.Message
+		default:
+			msg = layer.Err.Error()
+		}
+		return false
+	})
+	return msg
+}
+
+// chainMsg 由外向内使用 ": " 拼接每一层的错误信息
+// 非goerr产生的错误与多错误的完整错误信息已包含其内层信息，拼接后停止
+func chainMsg(err error) string {
+	var msgs []string
+	Walk(err, func(layer Layer) bool {
+		switch layer.Kind {
+		case LayerStack:
+			return true
+		case LayerMessage, LayerCode:
+			if layer.Message != "" {
+				msgs = append(msgs, layer.Message)
+			}
+			// 通过 %w 创建的错误信息已包含原因错误的信息
+			w, ok := layer.Err.(*withMessage)
+			return !ok || !w.wrapped
+		}
+		msgs = append(msgs, layer.Err.Error())
+		return false
+	})
+	return strings.Join(msgs, ": ")
+}
+
+// codedMsg 获取最外层错误码的信息，不会进入多错误的分支中查找
+func codedMsg(err error) (string, bool) {
+	var msg string
+	var ok bool
+	Walk(err, func(layer Layer) bool {
+		switch layer.Kind {
+		case LayerCode:
+			msg, ok = layer.Message, true
+			return false
+		case LayerJoin:
+			return false
+		}
+		return true
+	})
+	return msg, ok
+}
+
+// MessagePolicy 对外暴露错误信息的策略，避免内部错误信息通过接口泄露给调用方
+type MessagePolicy int
+
+const (
+	// PublicClient 客户端错误（HTTP码为4xx）暴露 ParseCode 得到的错误信息，
+	// 其余错误使用错误码注册时的信息，默认策略
+	PublicClient MessagePolicy = iota
+	// PublicAll 全部暴露 ParseCode 得到的错误信息
+	PublicAll
+	// PublicCode 全部使用错误码注册时的信息
+	PublicCode
+)
+
+var messagePolicy atomic.Int32
+
+// SetMessagePolicy 设置对外暴露错误信息的策略
+// 作用于 PublicMessage 以及各协议的错误响应
+func SetMessagePolicy(policy MessagePolicy) {
+	messagePolicy.Store(int32(policy))
+}
+
+// PublicMessage 按照对外暴露错误信息的策略获取可以返回给调用方的错误信息
+// 错误码未注册信息时使用HTTP码对应的状态文本
+func PublicMessage(err error) string {
+	code := ParseCode(err)
+	switch policy := MessagePolicy(messagePolicy.Load()); {
+	case policy == PublicAll,
+		policy == PublicClient && code.HttpCode >= 400 && code.HttpCode < 500:
+		return code.Msg
+	}
+	if msg := getCode(code.BusinessCode).Message; msg != "" {
+		return msg
+	}
+	if code.HttpCode < 400 {
+		// 未携带错误码的错误按服务端错误处理，避免返回 OK 等非错误状态文本
+		return http.StatusText(http.StatusInternalServerError)
+	}
+	return http.StatusText(code.HttpCode)
+}
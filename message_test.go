@@ -0,0 +1,79 @@
+package goerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestMessageSuite struct {
+	suite.Suite
+	dbErr, wrapErr, codeErr, outerErr error
+}
+
+func (s *TestMessageSuite) SetupTest() {
+	NewServiceUnavailable(3801, "service busy")
+	s.dbErr = errors.New("connection refused")
+	s.wrapErr = Wrap(s.dbErr, "load user")
+	s.codeErr = WithCode[int](s.wrapErr, 3801)
+	s.outerErr = Wrap(s.codeErr, "get profile")
+}
+
+func (s *TestMessageSuite) TearDownTest() {
+	SetMessageMode(MessageOuter)
+	SetMessagePolicy(PublicClient)
+}
+
+func (s *TestMessageSuite) TestOuter() {
+	s.Equal("load user", s.wrapErr.Error())
+	s.Equal("service busy", s.codeErr.Error())
+	s.Equal("get profile", s.outerErr.Error())
+	s.Equal("get profile", ParseCode(s.outerErr).Msg)
+}
+
+func (s *TestMessageSuite) TestChain() {
+	SetMessageMode(MessageChain)
+	s.Equal("load user: connection refused", s.wrapErr.Error())
+	s.Equal("service busy: load user: connection refused", s.codeErr.Error())
+	s.Equal("get profile: service busy: load user: connection refused", s.outerErr.Error())
+	s.Equal("get profile: service busy: load user: connection refused", ParseCode(s.outerErr).Msg)
+	s.Equal("get profile: service busy: load user: connection refused", fmt.Sprintf("%s", s.outerErr))
+	s.Equal("outer: load user: connection refused", Wrap(fmt.Errorf("outer: %w", s.wrapErr), "").Error())
+	s.Equal("joined: a; b", Wrap(Join(New("a"), errors.New("b")), "joined").Error())
+}
+
+func (s *TestMessageSuite) TestCoded() {
+	SetMessageMode(MessageCoded)
+	s.Equal("load user", s.wrapErr.Error())
+	s.Equal("service busy", s.codeErr.Error())
+	s.Equal("service busy", s.outerErr.Error())
+	s.Equal("service busy", ParseCode(s.outerErr).Msg)
+	s.Equal("connection refused", ParseCode(s.dbErr).Msg)
+}
+
+func (s *TestMessageSuite) TestPublic() {
+	NewBadRequest(3802, "bad profile")
+	badErr := WithCode[int](s.dbErr, 3802, WithMessage("profile id is empty"))
+	s.Equal("service busy", PublicMessage(s.outerErr))
+	s.Equal("profile id is empty", PublicMessage(badErr))
+	// 非错误状态的错误码按服务端错误处理
+	NewOK(3804, "")
+	s.Equal(http.StatusText(http.StatusInternalServerError),
+		PublicMessage(WithCode[int](s.dbErr, 3804)))
+
+	SetMessagePolicy(PublicAll)
+	s.Equal("get profile", PublicMessage(s.outerErr))
+
+	SetMessagePolicy(PublicCode)
+	s.Equal("bad profile", PublicMessage(badErr))
+	NewInternalError(3803, "")
+	s.Equal(http.StatusText(http.StatusInternalServerError),
+		PublicMessage(WithCode[int](s.dbErr, 3803)))
+}
+
+func TestMessage(t *testing.T) {
+	suite.Run(t, &TestMessageSuite{})
+}
@@ -1,5 +1,7 @@
 package goerr
 
+import "time"
+
 type Option func(*withCode)
 
 // WithMessage 替换错误码默认的提示信息
@@ -8,3 +10,33 @@ func WithMessage(msg string) Option {
 		w.Msg = msg
 	}
 }
+
+// WithDefaultMessage 错误码未注册提示信息时使用的提示信息
+func WithDefaultMessage(msg string) Option {
+	return func(w *withCode) {
+		if w.Msg == "" {
+			w.Msg = msg
+		}
+	}
+}
+
+// WithRetryAfter 设置调用方应在多久之后重试，常用于 NewTooManyRequests、NewServiceUnavailable 注册的错误码
+func WithRetryAfter(d time.Duration) Option {
+	return func(w *withCode) {
+		w.retryAfter = d
+	}
+}
+
+// WithRateLimit 设置限流信息，limit为时间窗口内允许的请求数，remaining为剩余请求数，reset为距离窗口重置的时间
+func WithRateLimit(limit, remaining int, reset time.Duration) Option {
+	return func(w *withCode) {
+		w.rateLimit = &RateLimit{Limit: limit, Remaining: remaining, Reset: reset}
+	}
+}
+
+// WithFields 为错误码错误附加字段校验失败信息，ParseCode 的结果会携带这些信息
+func WithFields(fields ...FieldViolation) Option {
+	return func(w *withCode) {
+		w.Fields = append(w.Fields, fields...)
+	}
+}
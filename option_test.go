@@ -19,6 +19,12 @@ func (s *TestOptionSuite) TestCode() {
 	w := &withCode{}
 	s.opt(w)
 	s.Equal("cover message", w.Msg)
+
+	WithDefaultMessage("default message")(w)
+	s.Equal("cover message", w.Msg)
+	w = &withCode{}
+	WithDefaultMessage("default message")(w)
+	s.Equal("default message", w.Msg)
 }
 
 func TestOption(t *testing.T) {
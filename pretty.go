@@ -0,0 +1,171 @@
+package goerr
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiRed   = "\x1b[31m"
+)
+
+// PrettyOption Pretty 输出配置项
+type PrettyOption func(*prettyConfig)
+
+type prettyConfig struct {
+	context int
+	color   bool
+}
+
+// WithContextLines 设置出错行前后展示的源码行数，默认为2
+func WithContextLines(n int) PrettyOption {
+	return func(c *prettyConfig) {
+		c.context = max(n, 0)
+	}
+}
+
+// WithColor 设置是否使用ANSI颜色输出
+// FprintPretty 在输出到终端时默认开启，Pretty 默认关闭
+func WithColor(color bool) PrettyOption {
+	return func(c *prettyConfig) {
+		c.color = color
+	}
+}
+
+// Pretty 获取便于本地开发阅读的错误信息
+// 依次输出错误链中每一层的错误信息与最内层的堆栈，对于项目自身的栈帧，
+// 会读取源文件展示出错行附近的源码，源文件不可用时仅输出文件与行号
+func Pretty(err error, options ...PrettyOption) string {
+	var b strings.Builder
+	writePretty(&b, err, options...)
+	return b.String()
+}
+
+// FprintPretty 将 Pretty 的结果写入w，w为终端时默认使用ANSI颜色
+func FprintPretty(w io.Writer, err error, options ...PrettyOption) {
+	options = append([]PrettyOption{WithColor(isTerminal(w))}, options...)
+	writePretty(w, err, options...)
+}
+
+func writePretty(w io.Writer, err error, options ...PrettyOption) {
+	if err == nil {
+		return
+	}
+	cfg := &prettyConfig{context: 2}
+	for _, option := range options {
+		option(cfg)
+	}
+	p := &prettyPrinter{w: w, prettyConfig: cfg, sources: map[string][]string{}}
+
+	var pcs []uintptr
+	first := true
+	Walk(err, func(layer Layer) bool {
+		if trace, ok := tracePCs(layer.Err); ok {
+			pcs = trace
+		}
+		if layer.Kind == LayerStack {
+			return true
+		}
+		if first {
+			p.paint(ansiBold+ansiRed, "error: ")
+			first = false
+		} else {
+			p.paint(ansiDim, "caused by: ")
+		}
+		// 多错误只输出其完整信息，不展开各分支
+		if layer.Kind == LayerJoin {
+			io.WriteString(w, layer.Err.Error()+"\n")
+			return false
+		}
+		io.WriteString(w, layer.Message+"\n")
+		return true
+	})
+
+	filter := loadStackConfig()
+	for _, pc := range pcs {
+		f := frame(pc)
+		name, file := f.name(), f.file()
+		if !filter.keep(name, file) {
+			continue
+		}
+		io.WriteString(w, "\n")
+		p.paint(ansiBold, name)
+		fmt.Fprintf(w, "\n\t%s:%d\n", f.path(), f.line())
+		if isOwnFile(file) {
+			p.snippet(file, f.line())
+		}
+	}
+}
+
+type prettyPrinter struct {
+	w io.Writer
+	*prettyConfig
+	sources map[string][]string
+}
+
+// paint 按照配置决定是否使用颜色输出文本
+func (p *prettyPrinter) paint(color, text string) {
+	if p.color {
+		io.WriteString(p.w, color+text+ansiReset)
+		return
+	}
+	io.WriteString(p.w, text)
+}
+
+// snippet 输出源文件中出错行及其前后各 context 行
+func (p *prettyPrinter) snippet(file string, line int) {
+	lines, ok := p.sources[file]
+	if !ok {
+		if data, err := os.ReadFile(file); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		p.sources[file] = lines
+	}
+	if line <= 0 || line > len(lines) {
+		return
+	}
+	from, to := max(line-p.context, 1), min(line+p.context, len(lines))
+	width := len(fmt.Sprint(to))
+	for i := from; i <= to; i++ {
+		text := strings.ReplaceAll(lines[i-1], "\t", "    ")
+		if i == line {
+			p.paint(ansiRed, fmt.Sprintf("\t> %*d | %s", width, i, text))
+		} else {
+			p.paint(ansiDim, fmt.Sprintf("\t  %*d | %s", width, i, text))
+		}
+		io.WriteString(p.w, "\n")
+	}
+}
+
+// goroot 编译时标准库源码所在目录，由 runtime.Callers 的源文件路径推算
+var goroot = func() string {
+	fn := runtime.FuncForPC(reflect.ValueOf(runtime.Callers).Pointer())
+	if fn == nil {
+		return ""
+	}
+	file, _ := fn.FileLine(fn.Entry())
+	dir, _, _ := strings.Cut(file, "/runtime/")
+	return dir + "/"
+}()
+
+// isOwnFile 判断源文件是否属于项目自身，标准库与模块缓存中的依赖均不属于
+func isOwnFile(file string) bool {
+	return !strings.Contains(file, "/pkg/mod/") && !strings.HasPrefix(file, goroot)
+}
+
+// isTerminal 判断w是否为终端
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
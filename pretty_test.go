@@ -0,0 +1,68 @@
+package goerr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestPrettySuite struct {
+	suite.Suite
+	err  error
+	line int
+}
+
+func (s *TestPrettySuite) SetupTest() {
+	_, _, s.line, _ = runtime.Caller(0)
+	s.err = Wrap(New("origin error"), "wrap error")
+	s.line++
+}
+
+// source 出错行前后第offset行在 Pretty 输出中的前缀
+func (s *TestPrettySuite) source(offset int) string {
+	marker := " "
+	if offset == 0 {
+		marker = ">"
+	}
+	return fmt.Sprintf("\t%s %d | ", marker, s.line+offset)
+}
+
+func (s *TestPrettySuite) TestPretty() {
+	out := Pretty(s.err)
+	s.True(strings.HasPrefix(out, "error: wrap error\ncaused by: origin error\n"))
+	s.Contains(out, "goerr.(*TestPrettySuite).SetupTest\n\t")
+	s.Contains(out, s.source(0)+`    s.err = Wrap(New("origin error"), "wrap error")`)
+	s.Contains(out, s.source(-2)+"func (s *TestPrettySuite) SetupTest() {\n")
+	s.Contains(out, s.source(2)+"}\n")
+	s.NotContains(out, "\x1b[")
+	// 标准库的栈帧不展示源码
+	s.NotContains(out, "func tRunner")
+}
+
+func (s *TestPrettySuite) TestOptions() {
+	out := Pretty(s.err, WithContextLines(0), WithColor(true))
+	s.Contains(out, ansiRed+s.source(0)+`    s.err = Wrap(New("origin error"), "wrap error")`+ansiReset)
+	s.NotContains(out, "| func (s *TestPrettySuite) SetupTest() {")
+
+	var buf bytes.Buffer
+	FprintPretty(&buf, s.err)
+	s.NotContains(buf.String(), "\x1b[")
+}
+
+func (s *TestPrettySuite) TestUnavailable() {
+	s.Empty(Pretty(nil))
+	s.Equal("error: plain error\n", Pretty(errors.New("plain error")))
+
+	p := &prettyPrinter{w: &bytes.Buffer{}, prettyConfig: &prettyConfig{}, sources: map[string][]string{}}
+	p.snippet("/not/exist.go", 1)
+	s.Empty(p.w.(*bytes.Buffer).String())
+}
+
+func TestPretty(t *testing.T) {
+	suite.Run(t, &TestPrettySuite{})
+}
@@ -1,24 +1,38 @@
 package goerr
 
 import (
-	"errors"
 	"fmt"
+	"slices"
 	"strings"
 )
 
 // New 创建新的错误，支持格式化占位符
+// 与 fmt.Errorf 相同，通过 %w 格式化的错误将作为原因错误，存在多个时合并为 Join 产生的多错误
 func New(format string, args ...any) error {
-	msg := format
-	if len(args) > 0 {
-		msg = fmt.Sprintf(format, args...)
+	msg, wrapped := formatMsg(format, args)
+	if len(wrapped) == 1 {
+		return &withMessage{
+			cause:   wrapStack(wrapped[0]),
+			msg:     msg,
+			wrapped: true,
+		}
+	}
+	if len(wrapped) > 1 {
+		return &withMessage{
+			cause:   Join(wrapped...),
+			msg:     msg,
+			wrapped: true,
+		}
 	}
 	return &fundamental{
-		msg:   msg,
-		stack: callers(),
+		msg:       msg,
+		stack:     callers(),
+		goroutine: currentGoroutine(),
 	}
 }
 
 // Wrap 包装已有错误，支持格式化占位符
+// 通过 %w 格式化的其他错误将与err一同作为原因错误，合并为 Join 产生的多错误
 func Wrap(err error, format string, args ...any) error {
 	if err == nil {
 		return nil
@@ -26,22 +40,63 @@ func Wrap(err error, format string, args ...any) error {
 	if len(strings.TrimSpace(format)) == 0 {
 		return err
 	}
-	msg := format
-	if len(args) > 0 {
-		msg = fmt.Sprintf(format, args...)
+	msg, wrapped := formatMsg(format, args)
+	causes := []error{err}
+	full := false
+	for _, w := range wrapped {
+		if sameError(w, err) {
+			full = true
+		} else {
+			causes = append(causes, w)
+		}
+	}
+	if len(causes) > 1 {
+		return &withMessage{
+			cause:   Join(causes...),
+			msg:     msg,
+			wrapped: full,
+		}
 	}
 	return &withMessage{
-		cause: wrapStack(err),
-		msg:   msg,
+		cause:   wrapStack(err),
+		msg:     msg,
+		wrapped: full,
 	}
 }
 
 // WithCode 创建带有错误码的error，支持格式化占位符
 // 使用option可以替换其中信息
 func WithCode[T codeType](err error, businessCode T, options ...Option) error {
-	code := getCode(serviceCode.Load() + int(businessCode))
+	ret := newCode(err, wrapStack(err), getCode(serviceCode.Load()+int(businessCode)), options)
+	if err != nil {
+		return ret
+	}
+	return &withStack{
+		error:     ret,
+		stack:     callers(),
+		goroutine: currentGoroutine().labeled(ret.labels),
+	}
+}
+
+// FromCode 使用完整的错误码信息创建错误码错误，不会拼接服务错误码，
+// 常用于还原来自其他服务的错误码错误
+func FromCode(err error, code ErrCode, options ...Option) error {
+	ret := newCode(err, wrapStack(err), code, options)
+	if err != nil {
+		return ret
+	}
+	return &withStack{
+		error:     ret,
+		stack:     callers(),
+		goroutine: currentGoroutine().labeled(ret.labels),
+	}
+}
+
+// newCode 使用错误码信息创建错误码错误，cause为err添加堆栈后的结果
+// 由调用方捕获堆栈，使堆栈从调用方的调用处开始
+func newCode(err, cause error, code ErrCode, options []Option) *withCode {
 	ret := &withCode{
-		cause:        wrapStack(err),
+		cause:        cause,
 		Msg:          code.Message,
 		HttpCode:     code.HttpCode,
 		BusinessCode: code.BusinessCode,
@@ -49,33 +104,38 @@ func WithCode[T codeType](err error, businessCode T, options ...Option) error {
 	for _, option := range options {
 		option(ret)
 	}
-
-	if err == nil {
-		return &withStack{
-			error: ret,
-			stack: callers(),
+	// 仅为本次创建的协程信息设置标签
+	if _, ok := err.(*withStack); !ok {
+		if w, ok := cause.(*withStack); ok {
+			w.goroutine = w.goroutine.labeled(ret.labels)
 		}
 	}
-
 	return ret
 }
 
+// WithStack 为错误添加堆栈，若错误链中已包含堆栈则直接返回原错误
 func WithStack(err error) error {
+	if hasStack(err) {
+		return err
+	}
 	return &withStack{
-		error: err,
-		stack: callers(),
+		error:     err,
+		stack:     callers(),
+		goroutine: currentGoroutine(),
 	}
 }
 
 // UnWrap 获取包装过的error
 // 若error1使用 Wrap 包装后产出错误error2
 // 当使用 UmWrap 后，返回的是error1
+// 遇到 Join 产生的多错误时停止并返回该多错误，可使用 Errors 获取其分支
 func UnWrap(err error) error {
 	type causer interface {
 		Cause() error
 	}
 
-	for err != nil {
+	var g chainGuard
+	for err != nil && g.enter(err) == chainOK {
 		cause, ok := err.(causer)
 		if !ok {
 			break
@@ -89,24 +149,28 @@ func UnWrap(err error) error {
 // 相比==判断错误，该方式会进行不断地类似于 UmWrap 的操作，
 // 将 UmWrap 后的错误进行比较
 func Is(err, target error) bool {
-	return errors.Is(err, target)
+	return is(err, target)
 }
 
 // As 匹配最外层的与target类型相同的error，将其赋值给target
-func As(err error, target any) bool { return errors.As(err, target) }
+func As(err error, target any) bool { return as(err, target) }
 
 // ParseCode 将错误解析为错误码错误
 // 若err不是错误码错误，则包裹传递错误，其他信息为默认错误码信息
 // 若err为错误码错误，将其转换，将最外层错误信息作为最终错误信息返回
+// 若错误链中包含字段校验错误 ValidationError，结果会携带全部字段校验失败信息
+// 若错误链中包含 Join 产生的多错误，按照 SetJoinPolicy 设置的策略选取错误码
 // 若想得到原始的错误码错误，可以使用As方法
 func ParseCode(err error) *withCode {
-	var target *withCode
-	if As(err, &target) {
+	if target := findCode(err); target != nil {
 		return &withCode{
 			cause:        target.cause,
 			Msg:          outerMsg(err),
 			HttpCode:     target.HttpCode,
 			BusinessCode: target.BusinessCode,
+			Fields:       append(slices.Clone(target.Fields), fieldsOf(err)...),
+			retryAfter:   target.retryAfter,
+			rateLimit:    target.rateLimit,
 		}
 	}
 
@@ -115,16 +179,18 @@ func ParseCode(err error) *withCode {
 		Msg:          err.Error(),
 		HttpCode:     defaultErrCode.HttpCode,
 		BusinessCode: serviceCode.Load(),
+		Fields:       fieldsOf(err),
 	}
 }
 
 // IsCode 判断某个错误是否为某个错误码
+// 与 Is 相同，会依次匹配错误链中的每一层以及 Join 产生的多错误的各分支
 func IsCode[T codeType](err error, code T) bool {
-	var target *withCode
-	if !As(err, &target) {
-		return false
-	}
-	return target.BusinessCode == serviceCode.Load()+int(code)
+	businessCode := serviceCode.Load() + int(code)
+	return walkTree(err, &chainGuard{}, func(e error) bool {
+		w, ok := e.(*withCode)
+		return ok && w.BusinessCode == businessCode
+	})
 }
 
 // SetAppCode 设置服务错误码
@@ -139,31 +205,36 @@ func String(err error) string {
 	return fmt.Sprintf("%+v", err)
 }
 
-// outerMsg 获取最外层的错误信息
-func outerMsg(err error) string {
-	if err == nil {
-		return ""
-	}
-	if e, ok := err.(*withCode); ok {
-		return e.Msg
+// formatMsg 格式化错误信息，同时返回通过 %w 格式化的错误
+func formatMsg(format string, args []any) (string, []error) {
+	if len(args) == 0 {
+		return format, nil
 	}
-	if e, ok := err.(*withMessage); ok {
-		return e.msg
+	if !strings.Contains(format, "%w") {
+		return fmt.Sprintf(format, args...), nil
 	}
-	if e, ok := err.(*withStack); ok {
-		return outerMsg(e.error)
+	err := fmt.Errorf(format, args...)
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return err.Error(), []error{e.Unwrap()}
+	case interface{ Unwrap() []error }:
+		return err.Error(), e.Unwrap()
 	}
-	return err.Error()
+	return err.Error(), nil
 }
 
 func wrapStack(err error) error {
 	switch err.(type) {
-	case *fundamental, *withCode, *withMessage, *withStack:
+	case *fundamental, *withCode, *withMessage, *withStack, *multiError, *BatchError:
 		return err
 	default:
+		if hasStack(err) {
+			return err
+		}
 		return &withStack{
-			error: err,
-			stack: callers(),
+			error:     err,
+			stack:     callers(),
+			goroutine: currentGoroutine(),
 		}
 	}
 }
@@ -2,6 +2,7 @@ package goerr
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -166,3 +167,76 @@ func TestServiceCode(t *testing.T) {
 	assert.Equal(t, http.StatusConflict, code.HttpCode)
 	assert.Equal(t, "retry error", code.Msg)
 }
+
+type tracedError struct {
+	*stack
+}
+
+func (t *tracedError) Error() string { return "traced error" }
+
+func TestWithStackSkip(t *testing.T) {
+	err := New("origin")
+	assert.Equal(t, err, WithStack(err))
+	assert.Equal(t, err, WithStack(Wrap(err, "wrap")).(*withMessage).cause)
+
+	traced := &tracedError{stack: callers()}
+	assert.Equal(t, error(traced), WithStack(traced))
+	assert.True(t, hasStack(fmt.Errorf("wrap: %w", traced)))
+	assert.False(t, hasStack(errors.New("plain")))
+}
+
+func TestWrapVerb(t *testing.T) {
+	NewNotFound(3901, "user not found")
+	origin := errors.New("origin error")
+	codeErr := WithCode[int](New("missing"), 3901)
+
+	err := New("load %s: %w", "1", origin)
+	assert.Equal(t, "load 1: origin error", err.Error())
+	assert.True(t, Is(err, origin))
+	assert.Equal(t, origin, UnWrap(err))
+	assert.True(t, hasStack(err))
+
+	err = New("load: %w", codeErr)
+	assert.True(t, IsCode(err, 3901))
+	assert.Equal(t, http.StatusNotFound, ParseCode(err).HttpCode)
+	assert.Equal(t, "load: user not found", ParseCode(err).Msg)
+
+	other := errors.New("other error")
+	err = New("%w and %w", origin, other)
+	assert.Equal(t, "origin error and other error", err.Error())
+	assert.True(t, Is(err, origin))
+	assert.True(t, Is(err, other))
+	assert.Len(t, Errors(err), 2)
+
+	err = Wrap(codeErr, "wrap: %w", codeErr)
+	assert.Equal(t, "wrap: user not found", err.Error())
+	assert.Equal(t, codeErr, err.(*withMessage).cause)
+
+	err = Wrap(codeErr, "wrap: %w", other)
+	assert.Len(t, Errors(err), 2)
+	assert.True(t, Is(err, other))
+	assert.Equal(t, 3901+serviceCode.Load(), ParseCode(err).BusinessCode)
+
+	assert.Equal(t, "bad %!w(string=x)", New("bad %w", "x").Error())
+
+	SetMessageMode(MessageChain)
+	defer SetMessageMode(MessageOuter)
+	assert.Equal(t, "load 1: origin error", New("load %s: %w", "1", origin).Error())
+	assert.Equal(t, "outer: load 1: origin error", Wrap(New("load %s: %w", "1", origin), "outer").Error())
+}
+
+func TestFromCode(t *testing.T) {
+	code := ErrCode{HttpCode: http.StatusNotFound, BusinessCode: 2020404, Message: "remote not found"}
+	err := FromCode(nil, code, WithMessage("user 1 not found"))
+	assert.True(t, hasStack(err))
+	assert.Equal(t, "user 1 not found", err.Error())
+	assert.Equal(t, 2020404, ParseCode(err).BusinessCode)
+	assert.Equal(t, http.StatusNotFound, ParseCode(err).HttpCode)
+	_, ok := LookupCode(2020404)
+	assert.False(t, ok)
+
+	origin := errors.New("origin")
+	err = FromCode(origin, code)
+	assert.True(t, Is(err, origin))
+	assert.Equal(t, "remote not found", ParseCode(err).Msg)
+}
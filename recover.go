@@ -0,0 +1,74 @@
+package goerr
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// recoverCode Recover 使用的错误码
+var recoverCode atomic.Int64
+
+func init() {
+	recoverCode.Store(ErrBasic)
+}
+
+// SetRecoverCode 设置 Recover 将panic转换为错误时使用的错误码，默认为 ErrBasic
+// 与 WithCode 相同，业务码会拼接应用码，错误码未注册时HTTP码为500
+func SetRecoverCode[T codeType](code T) {
+	recoverCode.Store(int64(code))
+}
+
+// PanicError 由 Recover 捕获的panic
+type PanicError struct {
+	// Value panic的值
+	Value any
+}
+
+func (p *PanicError) Error() string { return fmt.Sprintf("panic: %v", p.Value) }
+
+// Unwrap panic的值为错误时返回该错误
+func (p *PanicError) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// Recover 将panic转换为错误码错误并赋值给err，必须直接通过 defer 调用：
+//
+//	defer goerr.Recover(&err)
+//
+// 错误包含panic的值与发生panic处的堆栈，可以使用 As 获取 PanicError，
+// 错误码由 SetRecoverCode 设置，option可以替换其中信息
+func Recover(err *error, options ...Option) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	businessCode := serviceCode.Load() + int(recoverCode.Load())
+	code, ok := LookupCode(businessCode)
+	if !ok {
+		code = ErrCode{
+			HttpCode:     http.StatusInternalServerError,
+			BusinessCode: businessCode,
+			Message:      http.StatusText(http.StatusInternalServerError),
+		}
+	}
+	panicErr := &PanicError{Value: v}
+	*err = newCode(panicErr, &withStack{
+		error:     panicErr,
+		stack:     panicStack(),
+		goroutine: currentGoroutine(),
+	}, code, options)
+}
+
+// panicStack 获取发生panic处的堆栈，跳过 runtime 中处理panic的栈帧
+func panicStack() *stack {
+	var pcs [32]uintptr
+	var st stack = pcs[0:runtime.Callers(3, pcs[:])]
+	for len(st) > 0 && strings.HasPrefix(frame(st[0]).name(), "runtime.") {
+		st = st[1:]
+	}
+	return &st
+}
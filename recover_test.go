@@ -0,0 +1,67 @@
+package goerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func panicky(v any) (err error) {
+	defer Recover(&err)
+	panic(v)
+}
+
+func nilMap() (err error) {
+	defer Recover(&err, WithMessage("write failed"))
+	var m map[string]int
+	m["a"] = 1
+	return nil
+}
+
+func TestRecover(t *testing.T) {
+	err := panicky("boom")
+	assert.True(t, IsCode(err, ErrBasic))
+	var p *PanicError
+	assert.True(t, As(err, &p))
+	assert.Equal(t, "boom", p.Value)
+	assert.Equal(t, "panic: boom", p.Error())
+
+	out := fmt.Sprintf("%+v", err)
+	assert.Contains(t, out, "\npanic: boom\ngithub.com/yushengji/goerr.panicky\n")
+	assert.NotContains(t, out, "runtime.gopanic")
+
+	origin := errors.New("origin")
+	assert.True(t, Is(panicky(origin), origin))
+
+	err = nilMap()
+	assert.Equal(t, "write failed", err.Error())
+	assert.Contains(t, fmt.Sprintf("%+v", err), "\ngithub.com/yushengji/goerr.nilMap\n")
+
+	NewInternalError(4401, "worker crashed")
+	SetRecoverCode(4401)
+	defer SetRecoverCode(ErrBasic)
+	code := ParseCode(panicky(1))
+	assert.Equal(t, http.StatusInternalServerError, code.HttpCode)
+	assert.Equal(t, serviceCode.Load()+4401, code.BusinessCode)
+	assert.Equal(t, "worker crashed", code.Msg)
+
+	assert.NoError(t, func() (err error) {
+		defer Recover(&err)
+		return nil
+	}())
+
+	SetStackOptions(WithGoroutine())
+	defer SetStackOptions()
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("worker", "3"))
+	g, ok := GoroutineOf(func() (err error) {
+		defer Recover(&err, WithLabels(ctx))
+		panic("boom")
+	}())
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"worker": "3"}, g.Labels)
+}
@@ -0,0 +1,42 @@
+package goerr
+
+import "time"
+
+// RateLimit 错误码错误携带的限流信息
+type RateLimit struct {
+	// Limit 时间窗口内允许的请求数
+	Limit int `json:"limit"`
+	// Remaining 时间窗口内剩余的请求数
+	Remaining int `json:"remaining"`
+	// Reset 距离时间窗口重置的时间
+	Reset time.Duration `json:"reset"`
+}
+
+// RetryAfter 获取错误链中最外层通过 WithRetryAfter 设置的重试时间
+func RetryAfter(err error) (time.Duration, bool) {
+	var d time.Duration
+	walkTree(err, &chainGuard{}, func(e error) bool {
+		if c, ok := e.(*withCode); ok && c.retryAfter > 0 {
+			d = c.retryAfter
+			return true
+		}
+		return false
+	})
+	return d, d > 0
+}
+
+// RateLimitOf 获取错误链中最外层通过 WithRateLimit 设置的限流信息
+func RateLimitOf(err error) (RateLimit, bool) {
+	var limit *RateLimit
+	walkTree(err, &chainGuard{}, func(e error) bool {
+		if c, ok := e.(*withCode); ok && c.rateLimit != nil {
+			limit = c.rateLimit
+			return true
+		}
+		return false
+	})
+	if limit == nil {
+		return RateLimit{}, false
+	}
+	return *limit, true
+}
@@ -0,0 +1,35 @@
+package goerr
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	NewTooManyRequests(4501, "too many requests")
+	err := WithCode[int](errors.New("quota exceeded"), 4501,
+		WithRetryAfter(30*time.Second), WithRateLimit(100, 0, time.Minute))
+	err = Wrap(err, "create order")
+
+	d, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+	limit, ok := RateLimitOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, RateLimit{Limit: 100, Remaining: 0, Reset: time.Minute}, limit)
+
+	d, ok = RetryAfter(ParseCode(err))
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+
+	_, ok = RetryAfter(Join(New("other"), err))
+	assert.True(t, ok)
+
+	_, ok = RetryAfter(New("plain"))
+	assert.False(t, ok)
+	_, ok = RateLimitOf(WithCode[int](nil, 4501))
+	assert.False(t, ok)
+}
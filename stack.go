@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"io"
 	"path"
+	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+
+	pkgerrors "github.com/pkg/errors"
 )
 
 // 借助 pkg error 的堆栈实现
@@ -19,21 +23,76 @@ func (s *stack) Format(st fmt.State, verb rune) {
 	case 'v':
 		switch {
 		case st.Flag('+'):
-			for _, pc := range *s {
-				fmt.Fprintf(st, "\n%+v", frame(pc))
-			}
+			writeFrames(st, *s, loadStackConfig())
 		}
 	}
 }
 
-func (s *stack) StackTrace() stackTrace {
-	f := make([]frame, len(*s))
-	for i := 0; i < len(f); i++ {
-		f[i] = frame((*s)[i])
+// StackTrace 获取经过栈帧过滤的堆栈，
+// 满足 pkg/errors 的 interface{ StackTrace() errors.StackTrace } 约定
+func (s *stack) StackTrace() pkgerrors.StackTrace {
+	cfg := loadStackConfig()
+	f := make([]pkgerrors.Frame, 0, len(*s))
+	for _, pc := range *s {
+		if cfg.keep(frame(pc).name(), frame(pc).file()) {
+			f = append(f, pkgerrors.Frame(pc))
+		}
 	}
 	return f
 }
 
+// writeShared 输出与内层堆栈不重复的栈帧，共有的栈帧以 ... N more 代替，
+// N 仅统计通过过滤器的栈帧
+func (s *stack) writeShared(w io.Writer, inner []uintptr) {
+	cfg := loadStackConfig()
+	pcs := *s
+	if cfg.fullStacks || len(inner) == 0 {
+		writeFrames(w, pcs, cfg)
+		return
+	}
+	shared := sharedCount(pcs, inner)
+	writeFrames(w, pcs[:len(pcs)-shared], cfg)
+	if more := cfg.kept(pcs[len(pcs)-shared:]); more > 0 {
+		fmt.Fprintf(w, "\n\t... %d more", more)
+	}
+}
+
+// sharedCount 计算堆栈末尾与内层堆栈相同的栈帧数
+func sharedCount(pcs, inner []uintptr) int {
+	shared := 0
+	for shared < len(pcs) && shared < len(inner) &&
+		pcs[len(pcs)-1-shared] == inner[len(inner)-1-shared] {
+		shared++
+	}
+	return shared
+}
+
+// writeFrames 按照配置输出栈帧，被过滤的连续栈帧可折叠为一行
+func writeFrames(w io.Writer, pcs []uintptr, cfg *stackConfig) {
+	var dropped int
+	var packages []string
+	flush := func() {
+		if dropped > 0 && cfg.collapse {
+			fmt.Fprintf(w, "\n… %d frames in %s", dropped, strings.Join(packages, ", "))
+		}
+		dropped, packages = 0, packages[:0]
+	}
+	for _, pc := range pcs {
+		f := frame(pc)
+		name := f.name()
+		if !cfg.keep(name, f.file()) {
+			dropped++
+			if pkg := pkgPath(name); !slices.Contains(packages, pkg) {
+				packages = append(packages, pkg)
+			}
+			continue
+		}
+		flush()
+		fmt.Fprintf(w, "\n%+v", f)
+	}
+	flush()
+}
+
 func callers() *stack {
 	var pcs [32]uintptr
 	var st stack = pcs[0:runtime.Callers(3, pcs[:])]
@@ -60,6 +119,12 @@ func (f frame) file() string {
 	return file
 }
 
+// path returns the path of the source file trimmed according to
+// the stack configuration.
+func (f frame) path() string {
+	return loadStackConfig().trimPath(f.name(), f.file())
+}
+
 // line returns the line number of source code of the
 // function for this frame's pc.
 func (f frame) line() int {
@@ -71,6 +136,15 @@ func (f frame) line() int {
 	return line
 }
 
+// lineText returns the line number as text, or ? when line numbers
+// are masked by the stack configuration.
+func (f frame) lineText() string {
+	if loadStackConfig().maskLine {
+		return "?"
+	}
+	return strconv.Itoa(f.line())
+}
+
 // name returns the name of this function, if known.
 func (f frame) name() string {
 	fn := runtime.FuncForPC(f.pc())
@@ -99,12 +173,12 @@ func (f frame) Format(s fmt.State, verb rune) {
 		case s.Flag('+'):
 			io.WriteString(s, f.name())
 			io.WriteString(s, "\n\t")
-			io.WriteString(s, f.file())
+			io.WriteString(s, f.path())
 		default:
 			io.WriteString(s, path.Base(f.file()))
 		}
 	case 'd':
-		io.WriteString(s, strconv.Itoa(f.line()))
+		io.WriteString(s, f.lineText())
 	case 'n':
 		io.WriteString(s, funcname(f.name()))
 	case 'v':
@@ -121,50 +195,7 @@ func (f frame) MarshalText() ([]byte, error) {
 	if name == "unknown" {
 		return []byte(name), nil
 	}
-	return []byte(fmt.Sprintf("%s %s:%d", name, f.file(), f.line())), nil
-}
-
-// stackTrace is stack of Frames from innermost (newest) to outermost (oldest).
-type stackTrace []frame
-
-// Format formats the stack of Frames according to the fmt.Formatter interface.
-//
-//	%s	lists source files for each frame in the stack
-//	%v	lists the source file and line number for each frame in the stack
-//
-// Format accepts flags that alter the printing of some verbs, as follows:
-//
-//	%+v   Prints filename, function, and line number for each frame in the stack.
-func (st stackTrace) Format(s fmt.State, verb rune) {
-	switch verb {
-	case 'v':
-		switch {
-		case s.Flag('+'):
-			for _, f := range st {
-				io.WriteString(s, "\n")
-				f.Format(s, verb)
-			}
-		case s.Flag('#'):
-			fmt.Fprintf(s, "%#v", []frame(st))
-		default:
-			st.formatSlice(s, verb)
-		}
-	case 's':
-		st.formatSlice(s, verb)
-	}
-}
-
-// formatSlice will format this stackTrace into the given buffer as a slice of
-// frame, only valid when called with '%s' or '%v'.
-func (st stackTrace) formatSlice(s fmt.State, verb rune) {
-	io.WriteString(s, "[")
-	for i, f := range st {
-		if i > 0 {
-			io.WriteString(s, " ")
-		}
-		f.Format(s, verb)
-	}
-	io.WriteString(s, "]")
+	return []byte(fmt.Sprintf("%s %s:%s", name, f.path(), f.lineText())), nil
 }
 
 // funcname removes the path prefix component of a function's name reported by func.Name().
@@ -174,3 +205,46 @@ func funcname(name string) string {
 	i = strings.Index(name, ".")
 	return name[i+1:]
 }
+
+// stackOf 获取错误链中最外层的堆栈，同时识别实现了 pkg/errors 风格
+// StackTrace() 方法的第三方错误
+func stackOf(err error) []uintptr {
+	var ret []uintptr
+	unwrapChain(err, func(e error) bool {
+		pcs, ok := tracePCs(e)
+		ret = pcs
+		return !ok
+	})
+	return ret
+}
+
+// hasStack 判断错误链中是否已经包含堆栈
+func hasStack(err error) bool {
+	return stackOf(err) != nil
+}
+
+// tracePCs 获取单个错误自身携带的堆栈
+func tracePCs(err error) ([]uintptr, bool) {
+	switch e := err.(type) {
+	case *fundamental:
+		return *e.stack, true
+	case *withStack:
+		return *e.stack, true
+	}
+	// 第三方错误的StackTrace()返回值类型各不相同，
+	// 只要求其为元素是程序计数器的切片
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil, false
+	}
+	out := m.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil, false
+	}
+	trace := m.Call(nil)[0]
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+	return pcs, true
+}
@@ -0,0 +1,185 @@
+package goerr
+
+import (
+	"path"
+	"strings"
+	"sync/atomic"
+)
+
+// FrameFilter 栈帧过滤器，function为栈帧所在函数的全名，file为源文件绝对路径
+// 返回false时该栈帧将不会被输出
+type FrameFilter func(function, file string) bool
+
+// PathMode 栈帧源文件路径的输出方式
+type PathMode int
+
+const (
+	// PathFull 输出编译时的绝对路径
+	PathFull PathMode = iota
+	// PathModule 输出以包导入路径为前缀的路径，例如 github.com/yushengji/goerr/public.go
+	PathModule
+	// PathGOPATH 输出相对于GOPATH、模块缓存或GOROOT的路径，无法识别时输出绝对路径
+	PathGOPATH
+)
+
+// StackOption 堆栈输出配置项
+type StackOption func(*stackConfig)
+
+type stackConfig struct {
+	filters    []FrameFilter
+	pathMode   PathMode
+	collapse   bool
+	fullStacks bool
+	goroutine  bool
+	maskLine   bool
+	normalize  bool
+}
+
+var stackCfg atomic.Pointer[stackConfig]
+
+func init() {
+	stackCfg.Store(&stackConfig{})
+}
+
+// SetStackOptions 设置堆栈输出配置，作用于 %+v 格式化输出与结构化堆栈
+// 每次调用都会先恢复默认配置，再依次应用传入的配置项
+func SetStackOptions(options ...StackOption) {
+	cfg := &stackConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+	stackCfg.Store(cfg)
+}
+
+// WithFrameFilter 添加栈帧过滤器，只有全部过滤器都通过的栈帧才会被输出
+func WithFrameFilter(filters ...FrameFilter) StackOption {
+	return func(c *stackConfig) {
+		c.filters = append(c.filters, filters...)
+	}
+}
+
+// WithPathMode 设置源文件路径的输出方式
+func WithPathMode(mode PathMode) StackOption {
+	return func(c *stackConfig) {
+		c.pathMode = mode
+	}
+}
+
+// WithCollapse 将 %+v 输出中连续被过滤的栈帧折叠为一行，
+// 例如：… 7 frames in net/http
+func WithCollapse() StackOption {
+	return func(c *stackConfig) {
+		c.collapse = true
+	}
+}
+
+// WithFullStacks 在 %+v 输出中完整打印每一层的堆栈，
+// 默认情况下外层堆栈只输出与内层堆栈不重复的栈帧
+func WithFullStacks() StackOption {
+	return func(c *stackConfig) {
+		c.fullStacks = true
+	}
+}
+
+// WithLineMask 将输出中的行号替换为 ?，使输出不受代码行变动的影响
+func WithLineMask() StackOption {
+	return func(c *stackConfig) {
+		c.maskLine = true
+	}
+}
+
+// WithNormalize 规范化 %+v 输出，使其在不同机器上保持一致，便于使用golden文件进行快照测试
+// 源文件路径以 PathModule 方式输出，过滤 runtime、testing 包中的栈帧，且不输出协程信息，
+// 可配合 WithLineMask 屏蔽行号
+func WithNormalize() StackOption {
+	return func(c *stackConfig) {
+		c.normalize = true
+		c.pathMode = PathModule
+		c.filters = append(c.filters, DropPackage("runtime", "testing"))
+	}
+}
+
+// DropPackage 过滤指定包及其子包中的栈帧，例如 runtime、testing、net/http
+func DropPackage(packages ...string) FrameFilter {
+	return func(function, _ string) bool {
+		return !inPackages(pkgPath(function), packages)
+	}
+}
+
+// KeepModule 仅保留指定模块中的栈帧，例如 github.com/yushengji/goerr
+func KeepModule(modules ...string) FrameFilter {
+	return func(function, _ string) bool {
+		return inPackages(pkgPath(function), modules)
+	}
+}
+
+func loadStackConfig() *stackConfig {
+	return stackCfg.Load()
+}
+
+// keep 判断栈帧是否通过全部过滤器
+func (c *stackConfig) keep(function, file string) bool {
+	for _, filter := range c.filters {
+		if !filter(function, file) {
+			return false
+		}
+	}
+	return true
+}
+
+// kept 统计通过全部过滤器的栈帧数
+func (c *stackConfig) kept(pcs []uintptr) int {
+	n := 0
+	for _, pc := range pcs {
+		if c.keep(frame(pc).name(), frame(pc).file()) {
+			n++
+		}
+	}
+	return n
+}
+
+// trimPath 按照路径输出方式处理源文件路径
+func (c *stackConfig) trimPath(function, file string) string {
+	switch c.pathMode {
+	case PathModule:
+		pkg := pkgPath(function)
+		if pkg == "" {
+			return file
+		}
+		return pkg + "/" + path.Base(file)
+	case PathGOPATH:
+		if i := strings.LastIndex(file, "/pkg/mod/"); i >= 0 {
+			return file[i+len("/pkg/mod/"):]
+		}
+		if pkg := pkgPath(function); pkg != "" {
+			if i := strings.LastIndex(file, "/"+pkg+"/"); i >= 0 {
+				return file[i+1:]
+			}
+		}
+	}
+	return file
+}
+
+// inPackages 判断pkg是否为packages中某个包或其子包
+func inPackages(pkg string, packages []string) bool {
+	for _, p := range packages {
+		if pkg == p || strings.HasPrefix(pkg, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// pkgPath 从函数全名中获取包导入路径
+// 例如 net/http.(*conn).serve 的包导入路径为 net/http
+func pkgPath(function string) string {
+	if i := strings.Index(function, "["); i >= 0 {
+		function = function[:i]
+	}
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return function
+	}
+	return function[:slash+1+dot]
+}
@@ -0,0 +1,103 @@
+package goerr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestTraceSuite struct {
+	suite.Suite
+	err error
+}
+
+func (s *TestTraceSuite) SetupTest() {
+	s.err = New("trace error")
+}
+
+func (s *TestTraceSuite) TearDownTest() {
+	SetStackOptions()
+}
+
+func (s *TestTraceSuite) TestPkgPath() {
+	s.Equal("net/http", pkgPath("net/http.(*conn).serve"))
+	s.Equal("github.com/yushengji/goerr", pkgPath("github.com/yushengji/goerr.New"))
+	s.Equal("github.com/yushengji/goerr", pkgPath("github.com/yushengji/goerr.f[github.com/a/b.T]"))
+	s.Equal("runtime", pkgPath("runtime.goexit"))
+}
+
+func (s *TestTraceSuite) TestDefault() {
+	out := fmt.Sprintf("%+v", s.err)
+	s.Contains(out, "testing.tRunner")
+	s.Contains(out, "runtime.goexit")
+}
+
+func (s *TestTraceSuite) TestDropPackage() {
+	SetStackOptions(WithFrameFilter(DropPackage("runtime", "testing")))
+	out := fmt.Sprintf("%+v", s.err)
+	s.Contains(out, "goerr.(*TestTraceSuite).SetupTest")
+	s.NotContains(out, "testing.tRunner")
+	s.NotContains(out, "runtime.goexit")
+	s.NotContains(out, "…")
+
+	for _, f := range s.err.(*fundamental).StackTrace() {
+		s.False(strings.HasPrefix(frame(f).name(), "runtime."))
+	}
+}
+
+func (s *TestTraceSuite) TestKeepModule() {
+	SetStackOptions(WithFrameFilter(KeepModule("github.com/yushengji/goerr")), WithCollapse())
+	out := fmt.Sprintf("%+v", s.err)
+	s.Contains(out, "goerr.(*TestTraceSuite).SetupTest")
+	s.NotContains(out, "testing.tRunner")
+	s.Contains(out, "frames in ")
+	s.Contains(out, "testing")
+	s.True(strings.HasSuffix(out, "runtime"))
+}
+
+func (s *TestTraceSuite) TestPathMode() {
+	SetStackOptions(WithPathMode(PathModule))
+	s.Contains(fmt.Sprintf("%+v", s.err), "\tgithub.com/yushengji/goerr/trace_test.go:")
+
+	SetStackOptions(WithPathMode(PathGOPATH))
+	s.Contains(fmt.Sprintf("%+v", s.err), "\ttesting/testing.go:")
+}
+
+func (s *TestTraceSuite) TestMarshalText() {
+	SetStackOptions(WithPathMode(PathModule))
+	text, err := frame(s.err.(*fundamental).StackTrace()[0]).MarshalText()
+	s.NoError(err)
+	s.Contains(string(text), " github.com/yushengji/goerr/trace_test.go:")
+}
+
+func TestTrace(t *testing.T) {
+	suite.Run(t, &TestTraceSuite{})
+}
+
+func TestNormalize(t *testing.T) {
+	SetStackOptions(WithNormalize(), WithLineMask(), WithGoroutine())
+	defer SetStackOptions()
+
+	err := Wrap(New("origin error"), "wrap error")
+	assert.Equal(t, "wrap error\norigin error"+
+		"\ngithub.com/yushengji/goerr.TestNormalize\n\tgithub.com/yushengji/goerr/trace_test.go:?",
+		String(err))
+
+	text, _ := frame(err.(*withMessage).cause.(*fundamental).StackTrace()[0]).MarshalText()
+	assert.Equal(t, "github.com/yushengji/goerr.TestNormalize github.com/yushengji/goerr/trace_test.go:?", string(text))
+}
+
+func TestNormalizeShared(t *testing.T) {
+	SetStackOptions(WithNormalize(), WithLineMask())
+	defer SetStackOptions()
+
+	// 共有栈帧中被过滤的运行时与测试框架栈帧不计入 ... N more
+	err := WithCode[int](nil, 5)
+	_, out, _ := strings.Cut(fmt.Sprintf("%+v", err), "\n\n")
+	assert.Equal(t, "github.com/yushengji/goerr.WithCode[...]\n\tgithub.com/yushengji/goerr/public.go:?"+
+		"\ngithub.com/yushengji/goerr.TestNormalizeShared\n\tgithub.com/yushengji/goerr/trace_test.go:?"+
+		"\n\t... 1 more", out)
+}
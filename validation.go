@@ -0,0 +1,118 @@
+package goerr
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/puzpuzpuz/xsync"
+)
+
+var constraintMap *xsync.MapOf[string, string]
+
+func init() {
+	constraintMap = xsync.NewMapOf[string]()
+}
+
+// FieldViolation 字段校验失败信息
+type FieldViolation struct {
+	// Field 字段的JSON路径，例如 items[3].price
+	Field string `json:"field" xml:"field"`
+	// Constraint 未通过的约束名称，例如 required、min
+	Constraint string `json:"constraint" xml:"constraint"`
+	// Message 错误信息
+	Message string `json:"message" xml:"message"`
+	// Value 被拒绝的值
+	// +optional
+	Value any `json:"rejectedValue,omitempty" xml:"rejectedValue,omitempty"`
+}
+
+// ValidationError 字段级别的参数校验错误，可收集多个字段的校验失败信息
+// 通常作为 WithCode 的原因错误，例如 WithCode(v.Err(), ErrParam)，
+// ParseCode 的结果会携带全部字段校验失败信息
+type ValidationError struct {
+	violations []FieldViolation
+}
+
+// RegisterConstraint 注册约束的错误信息模板
+// 模板中的 {field} 替换为字段路径，{value} 替换为被拒绝的值，其余部分支持格式化占位符，
+// 例如 RegisterConstraint("min", "{field} must be at least %v")
+func RegisterConstraint(constraint, template string) {
+	constraintMap.Store(constraint, template)
+}
+
+// NewValidation 创建字段校验错误
+func NewValidation() *ValidationError {
+	return &ValidationError{}
+}
+
+// Add 记录字段校验失败，错误信息由约束注册的模板生成，params为模板的格式化参数
+// 约束未注册模板时，错误信息为约束名称
+func (v *ValidationError) Add(field, constraint string, value any, params ...any) *ValidationError {
+	msg := constraint
+	if template, ok := constraintMap.Load(constraint); ok {
+		// 先格式化再替换字段与值，避免其中的 % 被当作格式化占位符
+		msg = template
+		if len(params) > 0 {
+			msg = fmt.Sprintf(msg, params...)
+		}
+		msg = strings.NewReplacer("{field}", field, "{value}", fmt.Sprint(value)).Replace(msg)
+	}
+	return v.AddMessage(field, constraint, msg, value)
+}
+
+// AddMessage 记录字段校验失败，使用指定的错误信息
+func (v *ValidationError) AddMessage(field, constraint, message string, value any) *ValidationError {
+	v.violations = append(v.violations, FieldViolation{
+		Field:      field,
+		Constraint: constraint,
+		Message:    message,
+		Value:      value,
+	})
+	return v
+}
+
+// Violations 获取全部字段校验失败信息
+func (v *ValidationError) Violations() []FieldViolation {
+	return v.violations
+}
+
+// Err 存在字段校验失败时返回自身，否则返回nil
+func (v *ValidationError) Err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return v
+}
+
+func (v *ValidationError) Error() string {
+	msgs := make([]string, len(v.violations))
+	for i, f := range v.violations {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (v *ValidationError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			printVerbose(s, v)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, v.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", v.Error())
+	}
+}
+
+// fieldsOf 获取错误链中字段校验错误的全部字段校验失败信息
+func fieldsOf(err error) []FieldViolation {
+	var v *ValidationError
+	if As(err, &v) {
+		return v.violations
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package goerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestValidationSuite struct {
+	suite.Suite
+	validation *ValidationError
+}
+
+func (s *TestValidationSuite) SetupTest() {
+	NewBadRequest(ErrParam, "param error")
+	RegisterConstraint("min", "{field} must be at least %v")
+	RegisterConstraint("required", "{field} is required")
+
+	s.validation = NewValidation().
+		Add("items[3].price", "min", -1, 0).
+		Add("name", "required", nil).
+		Add("tag", "unknown", "x").
+		AddMessage("email", "email", "invalid email", "a@")
+}
+
+func (s *TestValidationSuite) TestViolations() {
+	s.Nil(NewValidation().Err())
+	s.Equal(s.validation, s.validation.Err())
+
+	violations := s.validation.Violations()
+	s.Len(violations, 4)
+	s.Equal(FieldViolation{"items[3].price", "min", "items[3].price must be at least 0", -1}, violations[0])
+	s.Equal("name is required", violations[1].Message)
+	s.Equal("unknown", violations[2].Message)
+	s.Equal("invalid email", violations[3].Message)
+	s.Equal("items[3].price: items[3].price must be at least 0; name: name is required; "+
+		"tag: unknown; email: invalid email", s.validation.Error())
+
+	RegisterConstraint("max", "{field} value {value} exceeds %v")
+	violation := NewValidation().Add("discount%", "max", "100%", 50).Violations()[0]
+	s.Equal("discount% value 100% exceeds 50", violation.Message)
+}
+
+func (s *TestValidationSuite) TestWithCode() {
+	err := WithCode(s.validation.Err(), ErrParam)
+	code := ParseCode(err)
+	s.Equal(http.StatusBadRequest, code.HttpCode)
+	s.Equal(s.validation.Violations(), code.Fields)
+
+	data, e := json.Marshal(code)
+	s.NoError(e)
+	s.Contains(string(data), `"fields":[{"field":"items[3].price","constraint":"min",`+
+		`"message":"items[3].price must be at least 0","rejectedValue":-1},`)
+	s.Contains(string(data), `{"field":"name","constraint":"required","message":"name is required"}`)
+
+	data, _ = json.Marshal(ParseCode(New("plain")))
+	s.NotContains(string(data), "fields")
+}
+
+func (s *TestValidationSuite) TestFormat() {
+	err := WithCode(s.validation.Err(), ErrParam)
+	code := ParseCode(err)
+	out := fmt.Sprintf("%+v", err)
+	s.True(strings.HasPrefix(out, fmt.Sprintf("param error [biz=%d http=%d]\n", code.BusinessCode, code.HttpCode)+
+		"validation failed:\n"+
+		"  - items[3].price: items[3].price must be at least 0 (min, rejected -1)\n"+
+		"  - name: name is required (required)\n"))
+	s.Contains(out, `  - email: invalid email (email, rejected "a@")`)
+}
+
+func TestValidation(t *testing.T) {
+	suite.Run(t, &TestValidationSuite{})
+}
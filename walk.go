@@ -0,0 +1,132 @@
+package goerr
+
+import "strings"
+
+// LayerKind 错误链中单层错误的类型
+type LayerKind int
+
+const (
+	// LayerMessage 携带错误信息的层，例如 New、Wrap 产生的错误
+	LayerMessage LayerKind = iota
+	// LayerCode 携带错误码的层，即 WithCode 产生的错误
+	LayerCode
+	// LayerStack 仅携带堆栈的层，例如 WithStack 产生的错误
+	LayerStack
+	// LayerJoin 包含多个分支的层，例如 Join、BatchError
+	LayerJoin
+	// LayerForeign 非goerr产生的错误
+	LayerForeign
+)
+
+func (k LayerKind) String() string {
+	switch k {
+	case LayerMessage:
+		return "message"
+	case LayerCode:
+		return "code"
+	case LayerStack:
+		return "stack"
+	case LayerJoin:
+		return "join"
+	case LayerForeign:
+		return "foreign"
+	}
+	return "unknown"
+}
+
+// Layer 错误链中的单层错误
+type Layer struct {
+	// Kind 层的类型
+	Kind LayerKind
+	// Message 该层自身的错误信息，不包含内层错误的信息，仅携带堆栈的层为空
+	Message string
+	// Code 该层的错误码，不携带错误码的层为nil
+	Code *ErrCode
+	// HasStack 该层自身是否携带堆栈
+	HasStack bool
+	// Depth 该层在错误树中的深度，最外层为0
+	Depth int
+	// Err 该层对应的错误
+	Err error
+}
+
+// Walk 由外向内先序遍历错误链中的每一层，f返回false时停止遍历
+// 支持 Unwrap() error、Cause() 与 Unwrap() []error 三种包装方式，
+// 遍历会检测循环引用，并受 SetMaxDepth 限制
+func Walk(err error, f func(layer Layer) bool) {
+	walkLayers(err, 0, &chainGuard{}, f)
+}
+
+// Chain 获取错误链中的全部层，顺序与 Walk 相同
+func Chain(err error) []Layer {
+	var layers []Layer
+	Walk(err, func(layer Layer) bool {
+		layers = append(layers, layer)
+		return true
+	})
+	return layers
+}
+
+// walkLayers 返回false表示遍历已被f停止
+func walkLayers(err error, depth int, g *chainGuard, f func(layer Layer) bool) bool {
+	defer g.reset(g.mark())
+	for err != nil && g.enter(err) == chainOK {
+		next, branches := unwrapOnce(err)
+		if !f(newLayer(err, next, depth)) {
+			return false
+		}
+		depth++
+		for _, branch := range branches {
+			if !walkLayers(branch, depth, g, f) {
+				return false
+			}
+		}
+		err = next
+	}
+	return true
+}
+
+// unwrapOnce 获取错误的下一层，或多错误的全部分支
+func unwrapOnce(err error) (error, []error) {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap(), nil
+	case interface{ Cause() error }:
+		return e.Cause(), nil
+	case interface{ Unwrap() []error }:
+		return nil, e.Unwrap()
+	}
+	return nil, nil
+}
+
+func newLayer(err, next error, depth int) Layer {
+	layer := Layer{Depth: depth, Err: err}
+	switch e := err.(type) {
+	case *fundamental:
+		layer.Kind, layer.Message, layer.HasStack = LayerMessage, e.msg, true
+	case *withMessage:
+		layer.Kind, layer.Message = LayerMessage, e.msg
+	case *withCode:
+		layer.Kind, layer.Message = LayerCode, e.Msg
+		layer.Code = &ErrCode{HttpCode: e.HttpCode, BusinessCode: e.BusinessCode, Message: e.Msg}
+	case *withStack:
+		layer.Kind, layer.HasStack = LayerStack, true
+	case *ValidationError:
+		layer.Kind, layer.Message = LayerMessage, e.Error()
+	case *multiError:
+		layer.Kind = LayerJoin
+	case *BatchError:
+		layer.Kind, layer.Message = LayerJoin, e.Error()
+		if code := e.code(); code != nil {
+			layer.Code = &ErrCode{HttpCode: code.HttpCode, BusinessCode: code.BusinessCode, Message: code.Msg}
+		}
+	default:
+		layer.Kind, layer.Message = LayerForeign, err.Error()
+		// 类似 fmt.Errorf("load: %w", err) 的错误信息包含了内层错误信息，只保留自身的部分
+		if next != nil {
+			layer.Message = strings.TrimSuffix(layer.Message, ": "+next.Error())
+		}
+		_, layer.HasStack = tracePCs(err)
+	}
+	return layer
+}
@@ -0,0 +1,82 @@
+package goerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWalkSuite struct {
+	suite.Suite
+	rootErr error
+	err     error
+}
+
+func (s *TestWalkSuite) SetupTest() {
+	NewNotFound(3701, "not found")
+	s.rootErr = errors.New("root error")
+	s.err = Wrap(WithCode[int](fmt.Errorf("load: %w", s.rootErr), 3701), "wrap error")
+}
+
+func (s *TestWalkSuite) TestChain() {
+	layers := Chain(s.err)
+	s.Len(layers, 5)
+
+	s.Equal(LayerMessage, layers[0].Kind)
+	s.Equal("wrap error", layers[0].Message)
+	s.False(layers[0].HasStack)
+
+	s.Equal(LayerCode, layers[1].Kind)
+	s.Equal("not found", layers[1].Message)
+	s.Equal(&ErrCode{http.StatusNotFound, serviceCode.Load() + 3701, "not found"}, layers[1].Code)
+
+	s.Equal(LayerStack, layers[2].Kind)
+	s.True(layers[2].HasStack)
+	s.Empty(layers[2].Message)
+
+	s.Equal(LayerForeign, layers[3].Kind)
+	s.Equal("load", layers[3].Message)
+	s.Equal(LayerForeign, layers[4].Kind)
+	s.Equal("root error", layers[4].Message)
+	s.Equal(s.rootErr, layers[4].Err)
+	s.Equal(4, layers[4].Depth)
+	s.Nil(layers[4].Code)
+}
+
+func (s *TestWalkSuite) TestJoin() {
+	layers := Chain(Join(New("first"), s.rootErr))
+	s.Len(layers, 4)
+	s.Equal(LayerJoin, layers[0].Kind)
+	s.Equal("first", layers[1].Message)
+	s.Equal(1, layers[1].Depth)
+	s.Equal(LayerStack, layers[2].Kind)
+	s.Equal(1, layers[2].Depth)
+	s.Equal("root error", layers[3].Message)
+	s.Equal(2, layers[3].Depth)
+}
+
+func (s *TestWalkSuite) TestCause() {
+	layers := Chain(pkgerrors.WithMessage(pkgerrors.New("pkg error"), "pkg message"))
+	s.Len(layers, 2)
+	s.Equal("pkg message", layers[0].Message)
+	s.True(layers[1].HasStack)
+	s.Equal(LayerForeign.String(), layers[1].Kind.String())
+}
+
+func (s *TestWalkSuite) TestStop() {
+	var kinds []LayerKind
+	Walk(s.err, func(layer Layer) bool {
+		kinds = append(kinds, layer.Kind)
+		return layer.Kind != LayerCode
+	})
+	s.Equal([]LayerKind{LayerMessage, LayerCode}, kinds)
+	s.Empty(Chain(nil))
+}
+
+func TestWalk(t *testing.T) {
+	suite.Run(t, &TestWalkSuite{})
+}